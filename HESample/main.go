@@ -1,16 +1,26 @@
 package main
 
 import (
+	"casbinsvr/config"
 	"encoding/base64"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"github.com/ldsec/lattigo/bfv"
 	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
 	"strings"
 )
 
 var bfvobj *BFVObject
 
+var (
+	addr   = flag.String("addr", "localhost:55344", "HTTP listen address")
+	params = flag.Int("params", 0, "index of the lattigo bfv.DefaultParams parameter set")
+)
+
 type EncryptReq struct {
 	PublishKeyFile string `json:"publish_key_file"`
 	Plaintext string `json:"plaintext"`
@@ -236,9 +246,23 @@ func WriteToFile(filename, content string) {
 }
 
 
+// validateConfig rejects settings the service could only fail on later.
+func validateConfig() error {
+	if _, _, err := net.SplitHostPort(*addr); err != nil {
+		return fmt.Errorf("addr: %v", err)
+	}
+	if *params < 0 || *params >= len(bfv.DefaultParams) {
+		return fmt.Errorf("params must be between 0 and %d", len(bfv.DefaultParams)-1)
+	}
+	return nil
+}
+
 func main() {
+	if err := config.Parse("he", validateConfig); err != nil {
+		log.Fatal(err)
+	}
 
-	bfvobj = NewBFVObject(0)
+	bfvobj = NewBFVObject(*params)
 
 	//pks := NewPublishKeyStore(bfvobj)
 	//bytesPk, err := pks.Pk.MarshalBinary()
@@ -289,8 +313,8 @@ func main() {
 	http.HandleFunc("/encrypt", encryptFunc)
 	http.HandleFunc("/reencrypt", reencryptFunc)
 	http.HandleFunc("/decrypt", decryptFunc)
-	fmt.Println("HE Service Listen On localhost:55344...")
-	if err := http.ListenAndServe("localhost:55344", nil); err != nil {
+	fmt.Println("HE Service Listen On " + *addr + "...")
+	if err := http.ListenAndServe(*addr, nil); err != nil {
 		fmt.Println("ListenAndServe: ", err)
 	}
 }
@@ -0,0 +1,212 @@
+// Package auditlog writes access decisions to an append-only JSON lines file.
+//
+// Every entry carries the hash of the entry before it and its own hash over
+// that link and its contents, so editing, inserting, reordering or deleting
+// an entry breaks the chain at that point. Verify walks the chain. Removing
+// entries from the end cannot be detected from the file alone; compare the
+// last sequence number and hash with a copy kept elsewhere.
+package auditlog
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Request is the access request a decision was made for.
+type Request struct {
+	Sub      string                 `json:"sub"`
+	Dom      string                 `json:"dom,omitempty"`
+	Obj      string                 `json:"obj"`
+	Act      string                 `json:"act"`
+	SubAttrs map[string]interface{} `json:"sub_attrs,omitempty"`
+	ObjAttrs map[string]interface{} `json:"obj_attrs,omitempty"`
+	EnvAttrs map[string]interface{} `json:"env_attrs,omitempty"`
+}
+
+// Entry is one line of the audit log. Seq, Prev and Hash are filled in by
+// Append.
+type Entry struct {
+	Seq           uint64    `json:"seq"`
+	Time          time.Time `json:"time"`
+	Caller        string    `json:"caller"`
+	Method        string    `json:"method"`
+	Request       Request   `json:"request"`
+	Decision      bool      `json:"decision"`
+	Rule          []string  `json:"rule,omitempty"`
+	PolicyVersion uint64    `json:"policy_version"`
+	Prev          string    `json:"prev"`
+	Hash          string    `json:"hash,omitempty"`
+}
+
+// sum returns the hex SHA-256 of the entry encoded without its hash.
+func (e Entry) sum() (string, error) {
+	e.Hash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// Log appends hash-chained entries to a file.
+type Log struct {
+	path string
+	mu   sync.Mutex
+	f    *os.File
+	seq  uint64
+	last string
+}
+
+// Open opens or creates the log at path and continues the chain from its
+// last entry.
+func Open(path string) (*Log, error) {
+	l := &Log{path: path}
+	if f, err := os.Open(path); err == nil {
+		err = eachEntry(f, func(line int, e *Entry) error {
+			l.seq, l.last = e.Seq, e.Hash
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l.f = f
+	return l, nil
+}
+
+// Append links e to the previous entry and writes it.
+func (l *Log) Append(e *Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = l.seq + 1
+	e.Prev = l.last
+	hash, err := e.sum()
+	if err != nil {
+		return err
+	}
+	e.Hash = hash
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := l.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	l.seq, l.last = e.Seq, e.Hash
+	return nil
+}
+
+// Recent returns up to n of the latest entries, oldest first. Entries
+// appended while it reads are left out.
+func (l *Log) Recent(n int) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	l.mu.Lock()
+	fi, err := l.f.Stat()
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// keep the last n entries in a ring, grown as entries are read since n
+	// may be far more than the log holds
+	var (
+		ring  []Entry
+		total int
+	)
+	err = eachEntry(io.LimitReader(f, fi.Size()), func(line int, e *Entry) error {
+		if len(ring) < n {
+			ring = append(ring, *e)
+		} else {
+			ring[total%n] = *e
+		}
+		total++
+		return nil
+	})
+	if err != nil || len(ring) < n {
+		return ring, err
+	}
+	start := total % n
+	return append(append(make([]Entry, 0, len(ring)), ring[start:]...), ring[:start]...), nil
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	return l.f.Close()
+}
+
+// Verify checks the chain of the log read from r and returns the number of
+// entries and the hash of the last one. The error names the first line
+// where the chain is broken.
+func Verify(r io.Reader) (int, string, error) {
+	var (
+		n    int
+		seq  uint64
+		last string
+	)
+	err := eachEntry(r, func(line int, e *Entry) error {
+		if e.Seq != seq+1 {
+			return fmt.Errorf("line %d: sequence %d follows %d", line, e.Seq, seq)
+		}
+		if e.Prev != last {
+			return fmt.Errorf("line %d: previous hash does not match entry %d", line, seq)
+		}
+		sum, err := e.sum()
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if sum != e.Hash {
+			return fmt.Errorf("line %d: entry %d was modified", line, e.Seq)
+		}
+		n, seq, last = n+1, e.Seq, e.Hash
+		return nil
+	})
+	return n, last, err
+}
+
+// Each calls fn for every entry read from r in order, without verifying the
+// chain.
+func Each(r io.Reader, fn func(e *Entry) error) error {
+	return eachEntry(r, func(line int, e *Entry) error {
+		return fn(e)
+	})
+}
+
+func eachEntry(r io.Reader, fn func(line int, e *Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if err := fn(line, &e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,80 @@
+package auditlog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLog appends n entries to a new log and returns its lines.
+func writeLog(t *testing.T, n int) []string {
+	dir, err := ioutil.TempDir("", "auditlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		e := &Entry{Caller: "tester", Method: "/AccessControl/Check", Request: Request{Sub: "alice", Obj: "data1", Act: "read"}, Decision: i%2 == 0}
+		if err := l.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+func TestVerify(t *testing.T) {
+	lines := writeLog(t, 4)
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+		// broken is the line Verify must report, 0 if the chain holds
+		broken int
+	}{
+		{"intact", func(l []string) []string { return l }, 0},
+		{"edited decision", func(l []string) []string {
+			l[1] = strings.Replace(l[1], `"decision":false`, `"decision":true`, 1)
+			return l
+		}, 2},
+		{"edited request", func(l []string) []string {
+			l[2] = strings.Replace(l[2], `"act":"read"`, `"act":"write"`, 1)
+			return l
+		}, 3},
+		{"deleted first", func(l []string) []string { return l[1:] }, 1},
+		{"deleted middle", func(l []string) []string { return append(l[:1], l[2:]...) }, 2},
+		{"reordered", func(l []string) []string {
+			l[1], l[2] = l[2], l[1]
+			return l
+		}, 2},
+		{"duplicated", func(l []string) []string { return append(l[:2], l[1:]...) }, 3},
+	}
+	for _, test := range tests {
+		tampered := test.tamper(append([]string(nil), lines...))
+		n, _, err := Verify(strings.NewReader(strings.Join(tampered, "\n") + "\n"))
+		if test.broken == 0 {
+			if err != nil || n != len(lines) {
+				t.Errorf("%s: got %d entries, %v; want %d entries", test.name, n, err, len(lines))
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: Verify accepted the log", test.name)
+			continue
+		}
+		if want := fmt.Sprintf("line %d:", test.broken); !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("%s: got %q, want it to start with %q", test.name, err, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package boltadapter stores casbin policy rules in an embedded BoltDB file.
+//
+// Rules are kept in insertion order, which matters for the priority effect,
+// and every write runs in its own transaction, so a rule is either fully
+// stored or not at all.
+package boltadapter
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/casbin/casbin/model"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+var (
+	// rulesBucket maps a sequence number to an encoded rule.
+	rulesBucket = []byte("rules")
+	// indexBucket maps an encoded rule to its sequence number.
+	indexBucket = []byte("index")
+)
+
+// Adapter implements persist.Adapter on top of a BoltDB file.
+type Adapter struct {
+	db *bolt.DB
+}
+
+// NewAdapter opens or creates the database at path. BoltDB holds an
+// exclusive lock on the file until Close is called.
+func NewAdapter(path string) (*Adapter, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(rulesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(indexBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Adapter{db: db}, nil
+}
+
+// Close releases the database file.
+func (a *Adapter) Close() error {
+	return a.db.Close()
+}
+
+// LoadPolicy loads all policy rules from the storage.
+func (a *Adapter) LoadPolicy(m model.Model) error {
+	return a.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(rulesBucket).ForEach(func(_, v []byte) error {
+			ptype, rule, err := decodeRule(v)
+			if err != nil {
+				return err
+			}
+			sec := ptype[:1]
+			if ast, ok := m[sec][ptype]; ok {
+				ast.Policy = append(ast.Policy, rule)
+			}
+			return nil
+		})
+	})
+}
+
+// SavePolicy replaces all stored rules with the rules of the model.
+func (a *Adapter) SavePolicy(m model.Model) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{rulesBucket, indexBucket} {
+			if err := tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		for _, sec := range []string{"p", "g"} {
+			for ptype, ast := range m[sec] {
+				for _, rule := range ast.Policy {
+					if err := putRule(tx, ptype, rule); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// AddPolicy adds a policy rule to the storage.
+func (a *Adapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return putRule(tx, ptype, rule)
+	})
+}
+
+// RemovePolicy removes a policy rule from the storage.
+func (a *Adapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		key, err := encodeRule(ptype, rule)
+		if err != nil {
+			return err
+		}
+		return deleteRule(tx, key)
+	})
+}
+
+// RemoveFilteredPolicy removes policy rules that match the filter from the storage.
+func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		var matched [][]byte
+		err := tx.Bucket(rulesBucket).ForEach(func(_, v []byte) error {
+			t, rule, err := decodeRule(v)
+			if err != nil {
+				return err
+			}
+			if t == ptype && matchesFilter(rule, fieldIndex, fieldValues) {
+				matched = append(matched, append([]byte(nil), v...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range matched {
+			if err := deleteRule(tx, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func matchesFilter(rule []string, fieldIndex int, fieldValues []string) bool {
+	for i, v := range fieldValues {
+		if v == "" {
+			continue
+		}
+		if fieldIndex+i >= len(rule) || rule[fieldIndex+i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func putRule(tx *bolt.Tx, ptype string, rule []string) error {
+	key, err := encodeRule(ptype, rule)
+	if err != nil {
+		return err
+	}
+	index := tx.Bucket(indexBucket)
+	if index.Get(key) != nil {
+		return nil
+	}
+	rules := tx.Bucket(rulesBucket)
+	seq, err := rules.NextSequence()
+	if err != nil {
+		return err
+	}
+	id := make([]byte, 8)
+	binary.BigEndian.PutUint64(id, seq)
+	if err := rules.Put(id, key); err != nil {
+		return err
+	}
+	return index.Put(key, id)
+}
+
+func deleteRule(tx *bolt.Tx, key []byte) error {
+	index := tx.Bucket(indexBucket)
+	id := index.Get(key)
+	if id == nil {
+		return nil
+	}
+	if err := tx.Bucket(rulesBucket).Delete(id); err != nil {
+		return err
+	}
+	return index.Delete(key)
+}
+
+// encodeRule encodes ptype and rule as a JSON array, e.g. ["p","alice","data1","read"].
+func encodeRule(ptype string, rule []string) ([]byte, error) {
+	return json.Marshal(append([]string{ptype}, rule...))
+}
+
+func decodeRule(b []byte) (string, []string, error) {
+	var fields []string
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return "", nil, err
+	}
+	if len(fields) < 2 || fields[0] == "" {
+		return "", nil, errors.New("boltadapter: malformed rule " + string(b))
+	}
+	return fields[0], fields[1:], nil
+}
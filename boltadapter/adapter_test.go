@@ -0,0 +1,131 @@
+package boltadapter
+
+import (
+	"github.com/casbin/casbin/model"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testModel = `
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act, eft
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = priority(p.eft) || deny
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act
+`
+
+func newModel() model.Model {
+	m := make(model.Model)
+	m.LoadModelFromText(testModel)
+	return m
+}
+
+// load returns the stored "p" and "g" rules in load order.
+func load(t *testing.T, a *Adapter) (p, g [][]string) {
+	m := newModel()
+	if err := a.LoadPolicy(m); err != nil {
+		t.Fatal(err)
+	}
+	return m["p"]["p"].Policy, m["g"]["g"].Policy
+}
+
+func check(t *testing.T, step string, a *Adapter, wantP, wantG [][]string) {
+	t.Helper()
+	p, g := load(t, a)
+	if !reflect.DeepEqual(p, wantP) || !reflect.DeepEqual(g, wantG) {
+		t.Fatalf("%s: got p %v, g %v; want p %v, g %v", step, p, g, wantP, wantG)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boltadapter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "policy.db")
+	a, err := NewAdapter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { a.Close() }()
+
+	// deliberately not in sorted order; the priority effect depends on it
+	rules := [][]string{
+		{"bob", "data2", "write", "allow"},
+		{"alice", "data1", "write", "deny"},
+		{"admin", "data1", "write", "allow"},
+		{"admin", "data2", "read", "allow"},
+		{"alice", "data2", "read", "deny"},
+	}
+	for _, rule := range rules {
+		if err := a.AddPolicy("p", "p", rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.AddPolicy("g", "g", []string{"alice", "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	// adding a stored rule again changes nothing
+	if err := a.AddPolicy("p", "p", rules[0]); err != nil {
+		t.Fatal(err)
+	}
+	check(t, "add", a, rules, [][]string{{"alice", "admin"}})
+
+	if err := a.RemovePolicy("p", "p", rules[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RemovePolicy("p", "p", []string{"nobody", "data1", "read", "allow"}); err != nil {
+		t.Fatal(err)
+	}
+	check(t, "remove", a, [][]string{rules[0], rules[2], rules[3], rules[4]}, [][]string{{"alice", "admin"}})
+
+	// an empty filter value matches any value
+	if err := a.RemoveFilteredPolicy("p", "p", 1, "data2", "", "allow"); err != nil {
+		t.Fatal(err)
+	}
+	check(t, "filtered remove", a, [][]string{rules[2], rules[4]}, [][]string{{"alice", "admin"}})
+
+	// a removed rule added again goes to the end
+	if err := a.AddPolicy("p", "p", rules[0]); err != nil {
+		t.Fatal(err)
+	}
+	check(t, "add after remove", a, [][]string{rules[2], rules[4], rules[0]}, [][]string{{"alice", "admin"}})
+
+	m := newModel()
+	m["p"]["p"].Policy = [][]string{rules[3], rules[1]}
+	m["g"]["g"].Policy = [][]string{{"bob", "admin"}}
+	if err := a.SavePolicy(m); err != nil {
+		t.Fatal(err)
+	}
+	check(t, "save", a, [][]string{rules[3], rules[1]}, [][]string{{"bob", "admin"}})
+
+	// the index was rebuilt with the rules
+	if err := a.RemovePolicy("p", "p", rules[3]); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddPolicy("p", "p", rules[1]); err != nil {
+		t.Fatal(err)
+	}
+	check(t, "edit after save", a, [][]string{rules[1]}, [][]string{{"bob", "admin"}})
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if a, err = NewAdapter(path); err != nil {
+		t.Fatal(err)
+	}
+	check(t, "reopen", a, [][]string{rules[1]}, [][]string{{"bob", "admin"}})
+}
@@ -1,19 +1,63 @@
 package main
 
 import (
+	"casbinsvr/config"
 	proto "casbinsvr/proto"
+	"casbinsvr/tlsconfig"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"log"
+	"net"
 )
 
 const (
 	address = "localhost:50051"
 )
 
+var (
+	addr    = flag.String("addr", address, "server address; any replica can answer checks")
+	explain = flag.Bool("explain", false, "print the matched policy rules and role paths for each check")
+	dom     = flag.String("dom", "", "domain (tenant) to check in, for servers running a domain model")
+	// TLS is used when any of the files is set
+	tlsCA         = flag.String("tls-ca", "", "PEM CA bundle to verify the server with, default the system roots")
+	tlsCert       = flag.String("tls-cert", "", "PEM client certificate for mutual TLS")
+	tlsKey        = flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tlsServerName = flag.String("tls-server-name", "", "name to verify the server certificate against, default the host of -addr")
+	token         = flag.String("token", "", "bearer token identifying this client to the server")
+)
+
+// validateConfig rejects settings the client could only fail on later.
+func validateConfig() error {
+	if _, _, err := net.SplitHostPort(*addr); err != nil {
+		return fmt.Errorf("addr: %v", err)
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		return errors.New("tls-cert and tls-key go together")
+	}
+	return config.CheckFiles(*tlsCA, *tlsCert, *tlsKey)
+}
+
 func main() {
-	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err := config.Parse("client", validateConfig); err != nil {
+		log.Fatal(err)
+	}
+	creds := grpc.WithInsecure()
+	if files := (tlsconfig.Files{Cert: *tlsCert, Key: *tlsKey, CA: *tlsCA}); !files.Empty() {
+		config, err := tlsconfig.Client(files, *tlsServerName)
+		if err != nil {
+			log.Fatalf("failed to configure TLS: %v", err)
+		}
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(config))
+	}
+	opts := []grpc.DialOption{creds}
+	if *token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(*token)))
+	}
+	conn, err := grpc.Dial(*addr, opts...)
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
@@ -27,11 +71,50 @@ func main() {
 		if sub == "exit" {
 			break
 		}
-		r, err := c.Check(context.Background(), &proto.AccessControlReq{Sub: sub, Obj: obj, Act: act})
+		req := &proto.AccessControlReq{Sub: sub, Obj: obj, Act: act, Dom: *dom}
+		if *explain {
+			r, err := c.ExplainCheck(context.Background(), req)
+			if err != nil {
+				log.Fatalf("could not explain access control: %v", err)
+			}
+			printExplain(r)
+			continue
+		}
+		r, err := c.Check(context.Background(), req)
 		if err != nil {
 			log.Fatalf("could not access control: %v", err)
 		}
-		log.Println("CheckResult:", r.GetRes())
+		log.Println("CheckResult:", r.GetRes(), "policy version:", r.GetPolicyVersion())
 	}
 
 }
+
+func printExplain(r *proto.ExplainResp) {
+	log.Println("CheckResult:", r.GetRes(), "effect:", r.GetEffect(), "policy version:", r.GetPolicyVersion())
+	if len(r.GetRules()) == 0 {
+		log.Println("  no policy rule matched")
+	}
+	for _, rule := range r.GetRules() {
+		mark := " "
+		if rule.GetDecisive() {
+			mark = "*"
+		}
+		log.Printf(" %s rule %d: p, %v (%s)\n", mark, rule.GetIndex(), rule.GetPolicy().GetParams(), rule.GetEft())
+		for _, g := range rule.GetRolePath() {
+			log.Printf("      via g, %v\n", g.GetParams())
+		}
+	}
+}
+
+// bearerToken sends a token in the "authorization" metadata of every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity is false so tokens also work on local plaintext
+// connections; use TLS anywhere else.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
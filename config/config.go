@@ -0,0 +1,181 @@
+// Package config lets every command of this module be configured from a
+// shared YAML file and the environment as well as from its flags.
+//
+// The file has a section per command whose keys are the command's flag
+// names, e.g.
+//
+//	server:
+//	  addr: ":50051"
+//	  cache-ttl: 1m
+//	gateway:
+//	  grpc-server-endpoint: "localhost:50051"
+//
+// A flag is also read from the environment variable CASBINSVR_<SECTION>_<FLAG>,
+// upper case with dashes replaced by underscores, e.g.
+// CASBINSVR_SERVER_CACHE_TTL. Flags given on the command line win over the
+// environment, which wins over the file, which wins over the defaults.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// ENV_PREFIX starts the names of all configuration environment variables.
+const ENV_PREFIX = "CASBINSVR_"
+
+// Sections are the commands that read the configuration file.
+var Sections = []string{"server", "gateway", "client", "he"}
+
+var (
+	file        = flag.String("config", os.Getenv(ENV_PREFIX+"CONFIG"), "YAML configuration file, see package config")
+	printConfig = flag.Bool("print-config", false, "print the effective configuration and exit")
+)
+
+// Parse parses the command line, applies the configuration file and the
+// environment to the flags of section that were not set on the command line
+// and runs validate, if not nil, on the result. With -print-config it then
+// prints the effective configuration and exits.
+func Parse(section string, validate func() error) error {
+	flag.Parse()
+	if err := Apply(flag.CommandLine, section, *file); err != nil {
+		return err
+	}
+	if validate != nil {
+		if err := validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+	}
+	if *printConfig {
+		if err := Print(os.Stdout, flag.CommandLine, section); err != nil {
+			return err
+		}
+		os.Exit(0)
+	}
+	return nil
+}
+
+// Apply sets the flags of fs that were not set explicitly from the
+// environment and, if path is not empty, from section of the file at path.
+func Apply(fs *flag.FlagSet, section, path string) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	if path != "" {
+		values, err := readSection(path, section)
+		if err != nil {
+			return err
+		}
+		for name, value := range values {
+			if !configurable(fs, name) {
+				return fmt.Errorf("%s: %s has no setting %q", path, section, name)
+			}
+			if explicit[name] {
+				continue
+			}
+			if err := fs.Set(name, value); err != nil {
+				return fmt.Errorf("%s: %s.%s: %v", path, section, name, err)
+			}
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] || !configurable(fs, f.Name) {
+			return
+		}
+		name := EnvName(section, f.Name)
+		if value, ok := os.LookupEnv(name); ok {
+			if e := fs.Set(f.Name, value); e != nil {
+				err = fmt.Errorf("%s: %v", name, e)
+			}
+		}
+	})
+	return err
+}
+
+// EnvName returns the environment variable of a flag of section.
+func EnvName(section, name string) string {
+	return ENV_PREFIX + strings.ToUpper(strings.Replace(section+"_"+name, "-", "_", -1))
+}
+
+// Print writes the effective value of every flag of fs as a section of the
+// configuration file.
+func Print(w io.Writer, fs *flag.FlagSet, section string) error {
+	var values yaml.MapSlice
+	fs.VisitAll(func(f *flag.Flag) {
+		if configurable(fs, f.Name) {
+			values = append(values, yaml.MapItem{Key: f.Name, Value: f.Value.String()})
+		}
+	})
+	b, err := yaml.Marshal(yaml.MapSlice{{Key: section, Value: values}})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// configurable reports whether the flag may be set from the file or the
+// environment; the flags of this package and unknown names may not.
+func configurable(fs *flag.FlagSet, name string) bool {
+	return fs.Lookup(name) != nil && name != "config" && name != "print-config"
+}
+
+func readSection(path, section string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]map[string]interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for name := range doc {
+		if !known(name) {
+			return nil, fmt.Errorf("%s: unknown section %q, want one of %s", path, name, strings.Join(Sections, ", "))
+		}
+	}
+	values := make(map[string]string, len(doc[section]))
+	for name, v := range doc[section] {
+		switch v.(type) {
+		case string, bool, int, float64:
+			values[name] = fmt.Sprint(v)
+		case nil:
+			values[name] = ""
+		default:
+			return nil, fmt.Errorf("%s: %s.%s must be a single value", path, section, name)
+		}
+	}
+	return values, nil
+}
+
+func known(section string) bool {
+	for _, s := range Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFiles returns an error for the first of the non-empty paths that
+// cannot be read.
+func CheckFiles(paths ...string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Command migrate imports a CSV policy into a BoltDB policy store, replacing
+// any rules already stored there. Run it while the server is stopped; BoltDB
+// allows only one process to open the file.
+package main
+
+import (
+	"casbinsvr/boltadapter"
+	"flag"
+	"fmt"
+	"github.com/casbin/casbin"
+	"log"
+)
+
+var (
+	modelPath  = flag.String("model", "server/rbac_model.conf", "casbin model the policy is written for")
+	policyPath = flag.String("csv", "server/rbac_policy.csv", "CSV policy to import")
+	dbPath     = flag.String("db", "server/rbac_policy.db", "BoltDB file to write")
+)
+
+func main() {
+	flag.Parse()
+
+	p, g, err := migrate(*modelPath, *policyPath, *dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("imported %d policy and %d grouping rules from %s into %s\n",
+		p, g, *policyPath, *dbPath)
+}
+
+// migrate replaces the rules stored in dbPath with the CSV policy and returns
+// how many policy and grouping rules it wrote.
+func migrate(modelPath, csvPath, dbPath string) (p, g int, err error) {
+	e, err := casbin.NewEnforcerSafe(modelPath, csvPath, false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load model: %v", err)
+	}
+	if err := e.LoadPolicy(); err != nil {
+		return 0, 0, fmt.Errorf("failed to load policy: %v", err)
+	}
+
+	a, err := boltadapter.NewAdapter(dbPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open %s: %v", dbPath, err)
+	}
+	defer a.Close()
+	if err := a.SavePolicy(e.GetModel()); err != nil {
+		return 0, 0, fmt.Errorf("failed to write %s: %v", dbPath, err)
+	}
+	return len(e.GetPolicy()), len(e.GetGroupingPolicy()), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"casbinsvr/boltadapter"
+	"github.com/casbin/casbin"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	const (
+		model  = "../server/rbac_with_deny_model.conf"
+		policy = "../server/rbac_with_deny_policy.csv"
+	)
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db := filepath.Join(dir, "policy.db")
+
+	// rules already in the store are replaced
+	a, err := boltadapter.NewAdapter(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddPolicy("p", "p", []string{"mallory", "data2", "write", "allow"}); err != nil {
+		t.Fatal(err)
+	}
+	a.Close()
+
+	p, g, err := migrate(model, policy, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != 3 || g != 2 {
+		t.Errorf("got %d policy and %d grouping rules, want 3 and 2", p, g)
+	}
+
+	want, err := casbin.NewEnforcerSafe(model, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, err = boltadapter.NewAdapter(db); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	got, err := casbin.NewEnforcerSafe(model, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.GetPolicy(), want.GetPolicy()) {
+		t.Errorf("got policy %v, want %v", got.GetPolicy(), want.GetPolicy())
+	}
+	if !reflect.DeepEqual(got.GetGroupingPolicy(), want.GetGroupingPolicy()) {
+		t.Errorf("got grouping policy %v, want %v", got.GetGroupingPolicy(), want.GetGroupingPolicy())
+	}
+	if !got.Enforce("bob", "data1", "write") || got.Enforce("alice", "data1", "write") {
+		t.Error("the imported policy decides differently from the CSV")
+	}
+}
+
+func TestMigrateMissingPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if _, _, err := migrate("../server/rbac_model.conf", filepath.Join(dir, "missing.csv"), filepath.Join(dir, "policy.db")); err == nil {
+		t.Error("migrate succeeded without a policy file")
+	}
+}
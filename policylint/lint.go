@@ -0,0 +1,413 @@
+package main
+
+import (
+	"fmt"
+	"github.com/casbin/casbin/model"
+	"sort"
+	"strings"
+)
+
+// finding severities
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// suspiciousActions read like an effect or a flag rather than an operation;
+// allow and deny belong in an eft column.
+var suspiciousActions = map[string]bool{
+	"permit": true, "unpermit": true, "allow": true, "deny": true,
+	"grant": true, "revoke": true, "true": true, "false": true,
+}
+
+// rule is one policy line, e.g. ptype "p" with values [alice data1 read].
+// line is 0 for stores without lines.
+type rule struct {
+	ptype  string
+	values []string
+	line   int
+}
+
+type finding struct {
+	Severity string   `json:"severity"`
+	Check    string   `json:"check"`
+	Message  string   `json:"message"`
+	Rule     []string `json:"rule,omitempty"`
+	Line     int      `json:"line,omitempty"`
+}
+
+// linter holds the policy indexed for the checks. Roles are the names that
+// are assigned to someone in a "g" rule; everything else is a user.
+type linter struct {
+	// positions of the fields of "p" rules, -1 if the model has none
+	sub, dom, obj, act, eft int
+	// gDom is set if "g" rules carry a domain
+	gDom bool
+
+	p, g []rule
+	// parents and members of each name by domain
+	parents, members map[string]map[string][]string
+	roles            map[string]bool
+	findings         []finding
+}
+
+// lint checks rules against model m. actions, if not nil, are the actions
+// requests have been seen for.
+func lint(m model.Model, rules []rule, actions map[string]bool) []finding {
+	l := &linter{
+		sub: -1, dom: -1, obj: -1, act: -1, eft: -1,
+		parents: make(map[string]map[string][]string),
+		members: make(map[string]map[string][]string),
+		roles:   make(map[string]bool),
+	}
+	if ast, ok := m["p"]["p"]; ok {
+		for i, token := range ast.Tokens {
+			switch token {
+			case "p_sub":
+				l.sub = i
+			case "p_dom":
+				l.dom = i
+			case "p_obj":
+				l.obj = i
+			case "p_act":
+				l.act = i
+			case "p_eft":
+				l.eft = i
+			}
+		}
+	}
+	if ast, ok := m["g"]["g"]; ok {
+		// without a request domain the third field is a validity schedule
+		if strings.Count(ast.Value, "_") >= 3 {
+			for _, token := range m["r"]["r"].Tokens {
+				l.gDom = l.gDom || token == "r_dom"
+			}
+		}
+	}
+
+	l.checkShape(m, rules)
+	l.checkDuplicates()
+	l.checkActions(actions)
+	for _, r := range l.g {
+		d := l.gDomain(r)
+		if l.parents[d] == nil {
+			l.parents[d] = make(map[string][]string)
+			l.members[d] = make(map[string][]string)
+		}
+		l.parents[d][r.values[0]] = append(l.parents[d][r.values[0]], r.values[1])
+		l.members[d][r.values[1]] = append(l.members[d][r.values[1]], r.values[0])
+		l.roles[r.values[1]] = true
+	}
+	l.checkCycles()
+	l.checkUndefinedRoles()
+	l.checkEmptyRoles()
+	l.checkShadowed(m)
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		return l.findings[i].Line < l.findings[j].Line
+	})
+	return l.findings
+}
+
+func (l *linter) report(severity, check string, r rule, format string, args ...interface{}) {
+	l.findings = append(l.findings, finding{
+		Severity: severity,
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+		Rule:     append([]string{r.ptype}, r.values...),
+		Line:     r.line,
+	})
+}
+
+// checkShape reports rules the model cannot load and keeps the well-formed
+// "p" and "g" rules for the other checks.
+func (l *linter) checkShape(m model.Model, rules []rule) {
+	for _, r := range rules {
+		if r.ptype == "" {
+			l.report(severityError, "malformed-rule", r, "the rule has no type")
+			continue
+		}
+		ast, ok := m[r.ptype[:1]][r.ptype]
+		if !ok {
+			l.report(severityError, "malformed-rule", r, "the model has no %q definition", r.ptype)
+			continue
+		}
+		want := len(ast.Tokens)
+		if r.ptype[:1] == "g" {
+			want = strings.Count(ast.Value, "_")
+		}
+		if len(r.values) != want {
+			l.report(severityError, "malformed-rule", r, "%s rule needs %d values, got %d", r.ptype, want, len(r.values))
+			continue
+		}
+		switch r.ptype {
+		case "p":
+			l.p = append(l.p, r)
+		case "g":
+			l.g = append(l.g, r)
+		}
+	}
+}
+
+func (l *linter) checkDuplicates() {
+	for _, rules := range [][]rule{l.p, l.g} {
+		first := make(map[string]rule)
+		for _, r := range rules {
+			k := strings.Join(r.values, "\x00")
+			if f, ok := first[k]; ok {
+				l.report(severityWarning, "duplicate-rule", r, "duplicate of %s", describe(f))
+				continue
+			}
+			first[k] = r
+		}
+	}
+}
+
+// checkActions reports actions that look like effects and, if actions is
+// not nil, actions no request was seen for. Patterns are not checked
+// against the requests.
+func (l *linter) checkActions(actions map[string]bool) {
+	if l.act < 0 {
+		return
+	}
+	for _, r := range l.p {
+		act := r.values[l.act]
+		if suspiciousActions[strings.ToLower(act)] {
+			l.report(severityWarning, "suspicious-action", r, "action %q reads like an effect or a flag, not an operation", act)
+		}
+		if actions != nil && !actions[act] && !strings.ContainsAny(act, "*()|") {
+			l.report(severityWarning, "unused-action", r, "no request in the audit log asked for action %q", act)
+		}
+	}
+}
+
+// pDomain returns the domain of a "p" rule and gDomain that of a "g" rule;
+// lookups in the other section go through pKey and gKey, since only one of
+// the sections may carry domains.
+func (l *linter) pDomain(r rule) string {
+	if l.dom < 0 {
+		return ""
+	}
+	return r.values[l.dom]
+}
+
+func (l *linter) gDomain(r rule) string {
+	if !l.gDom {
+		return ""
+	}
+	return r.values[2]
+}
+
+func (l *linter) pKey(dom string) string {
+	if l.dom < 0 {
+		return ""
+	}
+	return dom
+}
+
+func (l *linter) gKey(dom string) string {
+	if !l.gDom {
+		return ""
+	}
+	return dom
+}
+
+func (l *linter) checkCycles() {
+	reported := make(map[string]bool)
+	domains := make([]string, 0, len(l.parents))
+	for d := range l.parents {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	for _, d := range domains {
+		parents := l.parents[d]
+		state := make(map[string]int) // 1 on the path, 2 done
+		var path []string
+		var visit func(name string)
+		visit = func(name string) {
+			state[name] = 1
+			path = append(path, name)
+			for _, parent := range parents[name] {
+				switch state[parent] {
+				case 0:
+					visit(parent)
+				case 1:
+					start := 0
+					for path[start] != parent {
+						start++
+					}
+					cycle := append(append([]string(nil), path[start:]...), parent)
+					nodes := append([]string(nil), path[start:]...)
+					sort.Strings(nodes)
+					if k := d + "\x00" + strings.Join(nodes, "\x00"); !reported[k] {
+						reported[k] = true
+						l.report(severityError, "role-cycle", l.gRule(d, name, parent), "role inheritance cycle%s: %s", inDomain(d), strings.Join(cycle, " -> "))
+					}
+				}
+			}
+			path = path[:len(path)-1]
+			state[name] = 2
+		}
+		for _, name := range sortedKeys(parents) {
+			if state[name] == 0 {
+				visit(name)
+			}
+		}
+	}
+}
+
+// gRule returns the "g" rule assigning member to role in domain d.
+func (l *linter) gRule(d, member, role string) rule {
+	for _, r := range l.g {
+		if l.gDomain(r) == d && r.values[0] == member && r.values[1] == role {
+			return r
+		}
+	}
+	return rule{ptype: "g"}
+}
+
+// checkUndefinedRoles reports assignments to roles that grant nothing: no
+// "p" rule names them in the domain and they inherit from no other role.
+func (l *linter) checkUndefinedRoles() {
+	if l.sub < 0 {
+		return
+	}
+	granted := make(map[string]bool)
+	for _, r := range l.p {
+		granted[l.pDomain(r)+"\x00"+r.values[l.sub]] = true
+	}
+	for _, r := range l.g {
+		d, role := l.gDomain(r), r.values[1]
+		if granted[l.pKey(d)+"\x00"+role] || len(l.parents[d][role]) > 0 {
+			continue
+		}
+		l.report(severityError, "undefined-role", r, "%s is assigned to role %s, which has no permissions and no parent role%s", r.values[0], role, inDomain(d))
+	}
+}
+
+// checkEmptyRoles reports roles that are granted permissions but reach no
+// user in the domain of the grant.
+func (l *linter) checkEmptyRoles() {
+	if l.sub < 0 {
+		return
+	}
+	reported := make(map[string]bool)
+	for _, r := range l.p {
+		d, role := l.gKey(l.pDomain(r)), r.values[l.sub]
+		if !l.roles[role] || reported[d+"\x00"+role] {
+			continue
+		}
+		reported[d+"\x00"+role] = true
+		if !l.hasUser(d, role, make(map[string]bool)) {
+			l.report(severityWarning, "empty-role", r, "role %s has no members%s", role, inDomain(l.pDomain(r)))
+		}
+	}
+}
+
+func (l *linter) hasUser(d, role string, visited map[string]bool) bool {
+	visited[role] = true
+	for _, member := range l.members[d][role] {
+		if !l.roles[member] {
+			return true
+		}
+		if !visited[member] && l.hasUser(d, member, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// ancestors returns the roles name inherits in domain d, nearest first.
+func (l *linter) ancestors(d, name string) []string {
+	seen := map[string]bool{name: true}
+	var roles []string
+	queue := []string{name}
+	for len(queue) > 0 {
+		for _, parent := range l.parents[d][queue[0]] {
+			if !seen[parent] {
+				seen[parent] = true
+				roles = append(roles, parent)
+				queue = append(queue, parent)
+			}
+		}
+		queue = queue[1:]
+	}
+	return roles
+}
+
+// checkShadowed reports "p" rules that cannot change a decision: grants a
+// subject already has through one of its roles and, when deny overrides,
+// grants denied to the subject or one of its roles.
+func (l *linter) checkShadowed(m model.Model) {
+	if l.sub < 0 {
+		return
+	}
+	denyOverrides := false
+	if ast, ok := m["e"]["e"]; ok {
+		// casbin has escaped p.eft to p_eft
+		denyOverrides = l.eft >= 0 && strings.Contains(strings.Replace(ast.Value, " ", "", -1), "!some(where(p_eft==deny))")
+	}
+	field := func(r rule, i int) string {
+		if i < 0 {
+			return ""
+		}
+		return r.values[i]
+	}
+	key := func(sub string, r rule, eft string) string {
+		return strings.Join([]string{sub, field(r, l.dom), field(r, l.obj), field(r, l.act), eft}, "\x00")
+	}
+	// index of the first rule for each key
+	first := make(map[string]int)
+	for i, r := range l.p {
+		if _, ok := first[key(r.values[l.sub], r, field(r, l.eft))]; !ok {
+			first[key(r.values[l.sub], r, field(r, l.eft))] = i
+		}
+	}
+	for i, r := range l.p {
+		sub, eft := r.values[l.sub], field(r, l.eft)
+		if first[key(sub, r, eft)] != i {
+			// a duplicate, reported as such
+			continue
+		}
+		roles := l.ancestors(l.gKey(l.pDomain(r)), sub)
+		for _, role := range roles {
+			if j, ok := first[key(role, r, eft)]; ok {
+				l.report(severityWarning, "shadowed-rule", r, "%s already gets this through role %s, %s", sub, role, describe(l.p[j]))
+				break
+			}
+		}
+		if !denyOverrides || eft != "allow" {
+			continue
+		}
+		for _, s := range append([]string{sub}, roles...) {
+			if j, ok := first[key(s, r, "deny")]; ok {
+				l.report(severityWarning, "shadowed-rule", r, "never takes effect: %s is denied this by %s", s, describe(l.p[j]))
+				break
+			}
+		}
+	}
+}
+
+// describe names a rule by its line, or by its values without one.
+func describe(r rule) string {
+	if r.line > 0 {
+		return fmt.Sprintf("the rule on line %d", r.line)
+	}
+	return fmt.Sprintf("%s, %s", r.ptype, strings.Join(r.values, ", "))
+}
+
+func inDomain(d string) string {
+	if d == "" {
+		return ""
+	}
+	return " in domain " + d
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
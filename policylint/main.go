@@ -0,0 +1,176 @@
+// Command policylint checks a casbin model and policy for likely mistakes:
+// suspicious or unused actions, roles without members, users assigned to
+// roles that do not exist, inheritance cycles and duplicate or shadowed
+// rules. It prints one finding per line, or a JSON report with -format json,
+// and exits with status 1 if any finding is at least as severe as -fail-on,
+// or 2 if the files cannot be loaded, so it can gate merges:
+//
+//	go run ./policylint -model server/rbac_model.conf -policy server/rbac_policy.csv
+package main
+
+import (
+	"bufio"
+	"casbinsvr/auditlog"
+	"casbinsvr/boltadapter"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/casbin/casbin"
+	"github.com/casbin/casbin/model"
+	"os"
+	"strings"
+)
+
+var (
+	modelPath  = flag.String("model", "server/rbac_model.conf", "casbin model file")
+	policyPath = flag.String("policy", "server/rbac_policy.csv", "casbin policy file, or BoltDB file with -store bolt")
+	store      = flag.String("store", "csv", "policy store: csv or bolt")
+	// actions the server never saw are only reported with a decision log
+	auditLog = flag.String("audit-log", "", "decision audit log of the server, to find actions no request used")
+	format   = flag.String("format", "text", "output format: text or json")
+	failOn   = flag.String("fail-on", "warning", "lowest severity that fails the run: warning or error")
+)
+
+// report is the JSON output.
+type report struct {
+	Model    string    `json:"model"`
+	Policy   string    `json:"policy"`
+	Findings []finding `json:"findings"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+}
+
+func main() {
+	flag.Parse()
+	if *format != "text" && *format != "json" {
+		fatalf("unknown format %q, want text or json", *format)
+	}
+	if *failOn != "warning" && *failOn != "error" {
+		fatalf("unknown severity %q, want warning or error", *failOn)
+	}
+
+	e, err := casbin.NewEnforcerSafe(*modelPath)
+	if err != nil {
+		fatalf("failed to load model %s: %v", *modelPath, err)
+	}
+	m := e.GetModel()
+	var rules []rule
+	switch *store {
+	case "csv":
+		rules, err = readCSV(*policyPath)
+	case "bolt":
+		rules, err = readBolt(*policyPath, m)
+	default:
+		err = fmt.Errorf("unknown policy store %q, want csv or bolt", *store)
+	}
+	if err != nil {
+		fatalf("failed to load policy: %v", err)
+	}
+	var actions map[string]bool
+	if *auditLog != "" {
+		if actions, err = seenActions(*auditLog); err != nil {
+			fatalf("failed to read audit log: %v", err)
+		}
+	}
+
+	findings := lint(m, rules, actions)
+	r := report{Model: *modelPath, Policy: *policyPath, Findings: findings}
+	for _, f := range findings {
+		if f.Severity == severityError {
+			r.Errors++
+		} else {
+			r.Warnings++
+		}
+	}
+	if *format == "json" {
+		if r.Findings == nil {
+			r.Findings = []finding{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			fatalf("%v", err)
+		}
+	} else {
+		for _, f := range findings {
+			where := *policyPath
+			if f.Line > 0 {
+				where = fmt.Sprintf("%s:%d", where, f.Line)
+			}
+			fmt.Printf("%s: %s: %s [%s]\n", where, f.Severity, f.Message, f.Check)
+		}
+		fmt.Printf("%d errors, %d warnings\n", r.Errors, r.Warnings)
+	}
+	if r.Errors > 0 || *failOn == "warning" && r.Warnings > 0 {
+		os.Exit(1)
+	}
+}
+
+// readCSV reads the rules of a CSV policy with their line numbers, the way
+// casbin's file adapter splits them.
+func readCSV(path string) ([]rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var rules []rule
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		tokens := strings.Split(text, ",")
+		for i := range tokens {
+			tokens[i] = strings.TrimSpace(tokens[i])
+		}
+		rules = append(rules, rule{ptype: tokens[0], values: tokens[1:], line: line})
+	}
+	return rules, scanner.Err()
+}
+
+// readBolt reads the rules of a BoltDB policy store, which have no line
+// numbers.
+func readBolt(path string, m model.Model) ([]rule, error) {
+	a, err := boltadapter.NewAdapter(path)
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+	if err := a.LoadPolicy(m); err != nil {
+		return nil, err
+	}
+	var rules []rule
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			for _, values := range ast.Policy {
+				rules = append(rules, rule{ptype: ptype, values: values})
+			}
+			ast.Policy = nil
+		}
+	}
+	return rules, nil
+}
+
+// seenActions returns the actions of all requests in the audit log.
+func seenActions(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	actions := make(map[string]bool)
+	err = auditlog.Each(f, func(e *auditlog.Entry) error {
+		actions[e.Request.Act] = true
+		return nil
+	})
+	return actions, err
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "policylint: "+format+"\n", args...)
+	os.Exit(2)
+}
@@ -25,20 +25,175 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type PolicyEvent_Type int32
+
+const (
+	PolicyEvent_RELOAD PolicyEvent_Type = 0
+	PolicyEvent_ADD    PolicyEvent_Type = 1
+	PolicyEvent_REMOVE PolicyEvent_Type = 2
+)
+
+var PolicyEvent_Type_name = map[int32]string{
+	0: "RELOAD",
+	1: "ADD",
+	2: "REMOVE",
+}
+
+var PolicyEvent_Type_value = map[string]int32{
+	"RELOAD": 0,
+	"ADD":    1,
+	"REMOVE": 2,
+}
+
+func (x PolicyEvent_Type) String() string {
+	return proto.EnumName(PolicyEvent_Type_name, int32(x))
+}
+
+func (PolicyEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{18, 0}
+}
+
+type PolicyRevision_Kind int32
+
+const (
+	PolicyRevision_LOAD     PolicyRevision_Kind = 0
+	PolicyRevision_EDIT     PolicyRevision_Kind = 1
+	PolicyRevision_ROLLBACK PolicyRevision_Kind = 2
+)
+
+var PolicyRevision_Kind_name = map[int32]string{
+	0: "LOAD",
+	1: "EDIT",
+	2: "ROLLBACK",
+}
+
+var PolicyRevision_Kind_value = map[string]int32{
+	"LOAD":     0,
+	"EDIT":     1,
+	"ROLLBACK": 2,
+}
+
+func (x PolicyRevision_Kind) String() string {
+	return proto.EnumName(PolicyRevision_Kind_name, int32(x))
+}
+
+func (PolicyRevision_Kind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{22, 0}
+}
+
+type AttributeValue struct {
+	// Types that are valid to be assigned to Kind:
+	//	*AttributeValue_StringValue
+	//	*AttributeValue_NumberValue
+	//	*AttributeValue_BoolValue
+	Kind                 isAttributeValue_Kind `protobuf_oneof:"kind"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *AttributeValue) Reset()         { *m = AttributeValue{} }
+func (m *AttributeValue) String() string { return proto.CompactTextString(m) }
+func (*AttributeValue) ProtoMessage()    {}
+func (*AttributeValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{0}
+}
+
+func (m *AttributeValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttributeValue.Unmarshal(m, b)
+}
+func (m *AttributeValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttributeValue.Marshal(b, m, deterministic)
+}
+func (m *AttributeValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttributeValue.Merge(m, src)
+}
+func (m *AttributeValue) XXX_Size() int {
+	return xxx_messageInfo_AttributeValue.Size(m)
+}
+func (m *AttributeValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttributeValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttributeValue proto.InternalMessageInfo
+
+type isAttributeValue_Kind interface {
+	isAttributeValue_Kind()
+}
+
+type AttributeValue_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type AttributeValue_NumberValue struct {
+	NumberValue float64 `protobuf:"fixed64,2,opt,name=number_value,json=numberValue,proto3,oneof"`
+}
+
+type AttributeValue_BoolValue struct {
+	BoolValue bool `protobuf:"varint,3,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+func (*AttributeValue_StringValue) isAttributeValue_Kind() {}
+
+func (*AttributeValue_NumberValue) isAttributeValue_Kind() {}
+
+func (*AttributeValue_BoolValue) isAttributeValue_Kind() {}
+
+func (m *AttributeValue) GetKind() isAttributeValue_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return nil
+}
+
+func (m *AttributeValue) GetStringValue() string {
+	if x, ok := m.GetKind().(*AttributeValue_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (m *AttributeValue) GetNumberValue() float64 {
+	if x, ok := m.GetKind().(*AttributeValue_NumberValue); ok {
+		return x.NumberValue
+	}
+	return 0
+}
+
+func (m *AttributeValue) GetBoolValue() bool {
+	if x, ok := m.GetKind().(*AttributeValue_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*AttributeValue) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*AttributeValue_StringValue)(nil),
+		(*AttributeValue_NumberValue)(nil),
+		(*AttributeValue_BoolValue)(nil),
+	}
+}
+
 type AccessControlReq struct {
-	Sub                  string   `protobuf:"bytes,1,opt,name=sub,proto3" json:"sub,omitempty"`
-	Obj                  string   `protobuf:"bytes,2,opt,name=obj,proto3" json:"obj,omitempty"`
-	Act                  string   `protobuf:"bytes,3,opt,name=act,proto3" json:"act,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Sub                  string                     `protobuf:"bytes,1,opt,name=sub,proto3" json:"sub,omitempty"`
+	Obj                  string                     `protobuf:"bytes,2,opt,name=obj,proto3" json:"obj,omitempty"`
+	Act                  string                     `protobuf:"bytes,3,opt,name=act,proto3" json:"act,omitempty"`
+	Dom                  string                     `protobuf:"bytes,4,opt,name=dom,proto3" json:"dom,omitempty"`
+	SubAttrs             map[string]*AttributeValue `protobuf:"bytes,5,rep,name=sub_attrs,json=subAttrs,proto3" json:"sub_attrs,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ObjAttrs             map[string]*AttributeValue `protobuf:"bytes,6,rep,name=obj_attrs,json=objAttrs,proto3" json:"obj_attrs,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	EnvAttrs             map[string]*AttributeValue `protobuf:"bytes,7,rep,name=env_attrs,json=envAttrs,proto3" json:"env_attrs,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
+	XXX_unrecognized     []byte                     `json:"-"`
+	XXX_sizecache        int32                      `json:"-"`
 }
 
 func (m *AccessControlReq) Reset()         { *m = AccessControlReq{} }
 func (m *AccessControlReq) String() string { return proto.CompactTextString(m) }
 func (*AccessControlReq) ProtoMessage()    {}
 func (*AccessControlReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe87c01588b1ab0e, []int{0}
+	return fileDescriptor_fe87c01588b1ab0e, []int{1}
 }
 
 func (m *AccessControlReq) XXX_Unmarshal(b []byte) error {
@@ -80,8 +235,37 @@ func (m *AccessControlReq) GetAct() string {
 	return ""
 }
 
+func (m *AccessControlReq) GetDom() string {
+	if m != nil {
+		return m.Dom
+	}
+	return ""
+}
+
+func (m *AccessControlReq) GetSubAttrs() map[string]*AttributeValue {
+	if m != nil {
+		return m.SubAttrs
+	}
+	return nil
+}
+
+func (m *AccessControlReq) GetObjAttrs() map[string]*AttributeValue {
+	if m != nil {
+		return m.ObjAttrs
+	}
+	return nil
+}
+
+func (m *AccessControlReq) GetEnvAttrs() map[string]*AttributeValue {
+	if m != nil {
+		return m.EnvAttrs
+	}
+	return nil
+}
+
 type AccessControlResp struct {
 	Res                  bool     `protobuf:"varint,1,opt,name=res,proto3" json:"res,omitempty"`
+	PolicyVersion        uint64   `protobuf:"varint,2,opt,name=policy_version,json=policyVersion,proto3" json:"policy_version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -91,7 +275,7 @@ func (m *AccessControlResp) Reset()         { *m = AccessControlResp{} }
 func (m *AccessControlResp) String() string { return proto.CompactTextString(m) }
 func (*AccessControlResp) ProtoMessage()    {}
 func (*AccessControlResp) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe87c01588b1ab0e, []int{1}
+	return fileDescriptor_fe87c01588b1ab0e, []int{2}
 }
 
 func (m *AccessControlResp) XXX_Unmarshal(b []byte) error {
@@ -119,168 +303,2290 @@ func (m *AccessControlResp) GetRes() bool {
 	return false
 }
 
-type StringMessage struct {
-	Value                string   `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *AccessControlResp) GetPolicyVersion() uint64 {
+	if m != nil {
+		return m.PolicyVersion
+	}
+	return 0
 }
 
-func (m *StringMessage) Reset()         { *m = StringMessage{} }
-func (m *StringMessage) String() string { return proto.CompactTextString(m) }
-func (*StringMessage) ProtoMessage()    {}
-func (*StringMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fe87c01588b1ab0e, []int{2}
+type MatchedRule struct {
+	Index                int32     `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Policy               *Policy   `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	Eft                  string    `protobuf:"bytes,3,opt,name=eft,proto3" json:"eft,omitempty"`
+	RolePath             []*Policy `protobuf:"bytes,4,rep,name=role_path,json=rolePath,proto3" json:"role_path,omitempty"`
+	Decisive             bool      `protobuf:"varint,5,opt,name=decisive,proto3" json:"decisive,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
-func (m *StringMessage) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_StringMessage.Unmarshal(m, b)
+func (m *MatchedRule) Reset()         { *m = MatchedRule{} }
+func (m *MatchedRule) String() string { return proto.CompactTextString(m) }
+func (*MatchedRule) ProtoMessage()    {}
+func (*MatchedRule) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{3}
 }
-func (m *StringMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_StringMessage.Marshal(b, m, deterministic)
+
+func (m *MatchedRule) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MatchedRule.Unmarshal(m, b)
 }
-func (m *StringMessage) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_StringMessage.Merge(m, src)
+func (m *MatchedRule) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MatchedRule.Marshal(b, m, deterministic)
 }
-func (m *StringMessage) XXX_Size() int {
-	return xxx_messageInfo_StringMessage.Size(m)
+func (m *MatchedRule) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MatchedRule.Merge(m, src)
 }
-func (m *StringMessage) XXX_DiscardUnknown() {
-	xxx_messageInfo_StringMessage.DiscardUnknown(m)
+func (m *MatchedRule) XXX_Size() int {
+	return xxx_messageInfo_MatchedRule.Size(m)
+}
+func (m *MatchedRule) XXX_DiscardUnknown() {
+	xxx_messageInfo_MatchedRule.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_StringMessage proto.InternalMessageInfo
+var xxx_messageInfo_MatchedRule proto.InternalMessageInfo
 
-func (m *StringMessage) GetValue() string {
+func (m *MatchedRule) GetIndex() int32 {
 	if m != nil {
-		return m.Value
+		return m.Index
 	}
-	return ""
+	return 0
 }
 
-func init() {
-	proto.RegisterType((*AccessControlReq)(nil), "AccessControlReq")
-	proto.RegisterType((*AccessControlResp)(nil), "AccessControlResp")
-	proto.RegisterType((*StringMessage)(nil), "StringMessage")
+func (m *MatchedRule) GetPolicy() *Policy {
+	if m != nil {
+		return m.Policy
+	}
+	return nil
 }
 
-func init() { proto.RegisterFile("proto/access_control.proto", fileDescriptor_fe87c01588b1ab0e) }
-
-var fileDescriptor_fe87c01588b1ab0e = []byte{
-	// 253 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x90, 0x41, 0x4a, 0xc4, 0x30,
-	0x18, 0x85, 0xe9, 0x8c, 0x23, 0xfa, 0xc3, 0xc8, 0x4c, 0x50, 0x28, 0xd5, 0x85, 0x04, 0x06, 0xc4,
-	0x45, 0x82, 0xba, 0x73, 0x27, 0x45, 0x70, 0xe3, 0xa6, 0x1e, 0x40, 0xfe, 0x86, 0x9f, 0xb6, 0x63,
-	0xcd, 0x5f, 0x9b, 0xcc, 0xe0, 0x5a, 0xbc, 0x81, 0x47, 0xf3, 0x0a, 0x1e, 0x44, 0xd2, 0xb8, 0x99,
-	0xba, 0x7b, 0xef, 0x4b, 0xde, 0x7b, 0x21, 0x90, 0x75, 0x3d, 0x7b, 0xd6, 0x68, 0x0c, 0x39, 0xf7,
-	0x6c, 0xd8, 0xfa, 0x9e, 0x5b, 0x35, 0xc0, 0xec, 0xac, 0x62, 0xae, 0x5a, 0xd2, 0xd8, 0x35, 0x1a,
-	0xad, 0x65, 0x8f, 0xbe, 0x61, 0xeb, 0xe2, 0xa9, 0x7c, 0x80, 0xc5, 0xdd, 0x90, 0xca, 0x63, 0xa8,
-	0xa0, 0x37, 0xb1, 0x80, 0xa9, 0xdb, 0x94, 0x69, 0x72, 0x9e, 0x5c, 0x1c, 0x16, 0x41, 0x06, 0xc2,
-	0xe5, 0x3a, 0x9d, 0x44, 0xc2, 0xe5, 0x3a, 0x10, 0x34, 0x3e, 0x9d, 0x46, 0x82, 0xc6, 0xcb, 0x15,
-	0x2c, 0x47, 0x4d, 0xae, 0x0b, 0xd7, 0x7a, 0x72, 0x43, 0xd5, 0x41, 0x11, 0xa4, 0x5c, 0xc1, 0xfc,
-	0xc9, 0xf7, 0x8d, 0xad, 0x1e, 0xc9, 0x39, 0xac, 0x48, 0x1c, 0xc3, 0x6c, 0x8b, 0xed, 0x86, 0xfe,
-	0xf6, 0xa2, 0xb9, 0xfe, 0x4c, 0x60, 0xbe, 0x53, 0x27, 0x14, 0xcc, 0xf2, 0x9a, 0xcc, 0x8b, 0x58,
-	0xaa, 0xf1, 0x8b, 0x33, 0xa1, 0xfe, 0x4f, 0xe7, 0xb0, 0x77, 0x6f, 0x6a, 0x16, 0x47, 0x6a, 0x67,
-	0x2f, 0x1b, 0x79, 0x79, 0xfa, 0xf1, 0xfd, 0xf3, 0x35, 0x39, 0x91, 0x0b, 0xbd, 0xbd, 0xd2, 0xf4,
-	0x8e, 0xaf, 0x5d, 0x4b, 0x9a, 0x4c, 0xcd, 0xb7, 0xc9, 0x65, 0xb9, 0x3f, 0xfc, 0xd2, 0xcd, 0x6f,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0x18, 0xfc, 0x3a, 0x49, 0x61, 0x01, 0x00, 0x00,
+func (m *MatchedRule) GetEft() string {
+	if m != nil {
+		return m.Eft
+	}
+	return ""
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *MatchedRule) GetRolePath() []*Policy {
+	if m != nil {
+		return m.RolePath
+	}
+	return nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *MatchedRule) GetDecisive() bool {
+	if m != nil {
+		return m.Decisive
+	}
+	return false
+}
 
-// AccessControlClient is the client API for AccessControl service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type AccessControlClient interface {
-	Check(ctx context.Context, in *AccessControlReq, opts ...grpc.CallOption) (*AccessControlResp, error)
-	Echo(ctx context.Context, in *StringMessage, opts ...grpc.CallOption) (*StringMessage, error)
+type ExplainResp struct {
+	Res                  bool           `protobuf:"varint,1,opt,name=res,proto3" json:"res,omitempty"`
+	Effect               string         `protobuf:"bytes,2,opt,name=effect,proto3" json:"effect,omitempty"`
+	Rules                []*MatchedRule `protobuf:"bytes,3,rep,name=rules,proto3" json:"rules,omitempty"`
+	PolicyVersion        uint64         `protobuf:"varint,4,opt,name=policy_version,json=policyVersion,proto3" json:"policy_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
-type accessControlClient struct {
-	cc *grpc.ClientConn
+func (m *ExplainResp) Reset()         { *m = ExplainResp{} }
+func (m *ExplainResp) String() string { return proto.CompactTextString(m) }
+func (*ExplainResp) ProtoMessage()    {}
+func (*ExplainResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{4}
 }
 
-func NewAccessControlClient(cc *grpc.ClientConn) AccessControlClient {
-	return &accessControlClient{cc}
+func (m *ExplainResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExplainResp.Unmarshal(m, b)
+}
+func (m *ExplainResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExplainResp.Marshal(b, m, deterministic)
+}
+func (m *ExplainResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExplainResp.Merge(m, src)
+}
+func (m *ExplainResp) XXX_Size() int {
+	return xxx_messageInfo_ExplainResp.Size(m)
+}
+func (m *ExplainResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExplainResp.DiscardUnknown(m)
 }
 
-func (c *accessControlClient) Check(ctx context.Context, in *AccessControlReq, opts ...grpc.CallOption) (*AccessControlResp, error) {
-	out := new(AccessControlResp)
-	err := c.cc.Invoke(ctx, "/AccessControl/Check", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_ExplainResp proto.InternalMessageInfo
+
+func (m *ExplainResp) GetRes() bool {
+	if m != nil {
+		return m.Res
 	}
-	return out, nil
+	return false
 }
 
-func (c *accessControlClient) Echo(ctx context.Context, in *StringMessage, opts ...grpc.CallOption) (*StringMessage, error) {
-	out := new(StringMessage)
-	err := c.cc.Invoke(ctx, "/AccessControl/Echo", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ExplainResp) GetEffect() string {
+	if m != nil {
+		return m.Effect
 	}
-	return out, nil
+	return ""
 }
 
-// AccessControlServer is the server API for AccessControl service.
-type AccessControlServer interface {
-	Check(context.Context, *AccessControlReq) (*AccessControlResp, error)
-	Echo(context.Context, *StringMessage) (*StringMessage, error)
+func (m *ExplainResp) GetRules() []*MatchedRule {
+	if m != nil {
+		return m.Rules
+	}
+	return nil
 }
 
-// UnimplementedAccessControlServer can be embedded to have forward compatible implementations.
-type UnimplementedAccessControlServer struct {
+func (m *ExplainResp) GetPolicyVersion() uint64 {
+	if m != nil {
+		return m.PolicyVersion
+	}
+	return 0
 }
 
-func (*UnimplementedAccessControlServer) Check(ctx context.Context, req *AccessControlReq) (*AccessControlResp, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+type BatchCheckReq struct {
+	Reqs                 []*AccessControlReq `protobuf:"bytes,1,rep,name=reqs,proto3" json:"reqs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
-func (*UnimplementedAccessControlServer) Echo(ctx context.Context, req *StringMessage) (*StringMessage, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Echo not implemented")
+
+func (m *BatchCheckReq) Reset()         { *m = BatchCheckReq{} }
+func (m *BatchCheckReq) String() string { return proto.CompactTextString(m) }
+func (*BatchCheckReq) ProtoMessage()    {}
+func (*BatchCheckReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{5}
 }
 
-func RegisterAccessControlServer(s *grpc.Server, srv AccessControlServer) {
-	s.RegisterService(&_AccessControl_serviceDesc, srv)
+func (m *BatchCheckReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchCheckReq.Unmarshal(m, b)
+}
+func (m *BatchCheckReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchCheckReq.Marshal(b, m, deterministic)
+}
+func (m *BatchCheckReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchCheckReq.Merge(m, src)
+}
+func (m *BatchCheckReq) XXX_Size() int {
+	return xxx_messageInfo_BatchCheckReq.Size(m)
+}
+func (m *BatchCheckReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchCheckReq.DiscardUnknown(m)
 }
 
-func _AccessControl_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AccessControlReq)
-	if err := dec(in); err != nil {
-		return nil, err
+var xxx_messageInfo_BatchCheckReq proto.InternalMessageInfo
+
+func (m *BatchCheckReq) GetReqs() []*AccessControlReq {
+	if m != nil {
+		return m.Reqs
 	}
-	if interceptor == nil {
-		return srv.(AccessControlServer).Check(ctx, in)
+	return nil
+}
+
+type BatchCheckResp struct {
+	Resps                []*AccessControlResp `protobuf:"bytes,1,rep,name=resps,proto3" json:"resps,omitempty"`
+	PolicyVersion        uint64               `protobuf:"varint,2,opt,name=policy_version,json=policyVersion,proto3" json:"policy_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *BatchCheckResp) Reset()         { *m = BatchCheckResp{} }
+func (m *BatchCheckResp) String() string { return proto.CompactTextString(m) }
+func (*BatchCheckResp) ProtoMessage()    {}
+func (*BatchCheckResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{6}
+}
+
+func (m *BatchCheckResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchCheckResp.Unmarshal(m, b)
+}
+func (m *BatchCheckResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchCheckResp.Marshal(b, m, deterministic)
+}
+func (m *BatchCheckResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchCheckResp.Merge(m, src)
+}
+func (m *BatchCheckResp) XXX_Size() int {
+	return xxx_messageInfo_BatchCheckResp.Size(m)
+}
+func (m *BatchCheckResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchCheckResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchCheckResp proto.InternalMessageInfo
+
+func (m *BatchCheckResp) GetResps() []*AccessControlResp {
+	if m != nil {
+		return m.Resps
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/AccessControl/Check",
+	return nil
+}
+
+func (m *BatchCheckResp) GetPolicyVersion() uint64 {
+	if m != nil {
+		return m.PolicyVersion
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AccessControlServer).Check(ctx, req.(*AccessControlReq))
+	return 0
+}
+
+type WhoCanReq struct {
+	Obj                  string   `protobuf:"bytes,1,opt,name=obj,proto3" json:"obj,omitempty"`
+	Act                  string   `protobuf:"bytes,2,opt,name=act,proto3" json:"act,omitempty"`
+	Dom                  string   `protobuf:"bytes,3,opt,name=dom,proto3" json:"dom,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WhoCanReq) Reset()         { *m = WhoCanReq{} }
+func (m *WhoCanReq) String() string { return proto.CompactTextString(m) }
+func (*WhoCanReq) ProtoMessage()    {}
+func (*WhoCanReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{7}
+}
+
+func (m *WhoCanReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WhoCanReq.Unmarshal(m, b)
+}
+func (m *WhoCanReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WhoCanReq.Marshal(b, m, deterministic)
+}
+func (m *WhoCanReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WhoCanReq.Merge(m, src)
+}
+func (m *WhoCanReq) XXX_Size() int {
+	return xxx_messageInfo_WhoCanReq.Size(m)
+}
+func (m *WhoCanReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_WhoCanReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WhoCanReq proto.InternalMessageInfo
+
+func (m *WhoCanReq) GetObj() string {
+	if m != nil {
+		return m.Obj
 	}
-	return interceptor(ctx, in, info, handler)
+	return ""
 }
 
-func _AccessControl_Echo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StringMessage)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *WhoCanReq) GetAct() string {
+	if m != nil {
+		return m.Act
 	}
-	if interceptor == nil {
-		return srv.(AccessControlServer).Echo(ctx, in)
+	return ""
+}
+
+func (m *WhoCanReq) GetDom() string {
+	if m != nil {
+		return m.Dom
 	}
-	info := &grpc.UnaryServerInfo{
+	return ""
+}
+
+type WhoCanResp struct {
+	Users                []string `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	Roles                []string `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WhoCanResp) Reset()         { *m = WhoCanResp{} }
+func (m *WhoCanResp) String() string { return proto.CompactTextString(m) }
+func (*WhoCanResp) ProtoMessage()    {}
+func (*WhoCanResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{8}
+}
+
+func (m *WhoCanResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WhoCanResp.Unmarshal(m, b)
+}
+func (m *WhoCanResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WhoCanResp.Marshal(b, m, deterministic)
+}
+func (m *WhoCanResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WhoCanResp.Merge(m, src)
+}
+func (m *WhoCanResp) XXX_Size() int {
+	return xxx_messageInfo_WhoCanResp.Size(m)
+}
+func (m *WhoCanResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_WhoCanResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WhoCanResp proto.InternalMessageInfo
+
+func (m *WhoCanResp) GetUsers() []string {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+func (m *WhoCanResp) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+type RoleReq struct {
+	User                 string   `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Role                 string   `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	Dom                  string   `protobuf:"bytes,3,opt,name=dom,proto3" json:"dom,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoleReq) Reset()         { *m = RoleReq{} }
+func (m *RoleReq) String() string { return proto.CompactTextString(m) }
+func (*RoleReq) ProtoMessage()    {}
+func (*RoleReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{9}
+}
+
+func (m *RoleReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoleReq.Unmarshal(m, b)
+}
+func (m *RoleReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoleReq.Marshal(b, m, deterministic)
+}
+func (m *RoleReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoleReq.Merge(m, src)
+}
+func (m *RoleReq) XXX_Size() int {
+	return xxx_messageInfo_RoleReq.Size(m)
+}
+func (m *RoleReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoleReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoleReq proto.InternalMessageInfo
+
+func (m *RoleReq) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *RoleReq) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *RoleReq) GetDom() string {
+	if m != nil {
+		return m.Dom
+	}
+	return ""
+}
+
+type RoleList struct {
+	Names                []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoleList) Reset()         { *m = RoleList{} }
+func (m *RoleList) String() string { return proto.CompactTextString(m) }
+func (*RoleList) ProtoMessage()    {}
+func (*RoleList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{10}
+}
+
+func (m *RoleList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoleList.Unmarshal(m, b)
+}
+func (m *RoleList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoleList.Marshal(b, m, deterministic)
+}
+func (m *RoleList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoleList.Merge(m, src)
+}
+func (m *RoleList) XXX_Size() int {
+	return xxx_messageInfo_RoleList.Size(m)
+}
+func (m *RoleList) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoleList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoleList proto.InternalMessageInfo
+
+func (m *RoleList) GetNames() []string {
+	if m != nil {
+		return m.Names
+	}
+	return nil
+}
+
+type HasRoleResp struct {
+	Res                  bool     `protobuf:"varint,1,opt,name=res,proto3" json:"res,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HasRoleResp) Reset()         { *m = HasRoleResp{} }
+func (m *HasRoleResp) String() string { return proto.CompactTextString(m) }
+func (*HasRoleResp) ProtoMessage()    {}
+func (*HasRoleResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{11}
+}
+
+func (m *HasRoleResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HasRoleResp.Unmarshal(m, b)
+}
+func (m *HasRoleResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HasRoleResp.Marshal(b, m, deterministic)
+}
+func (m *HasRoleResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HasRoleResp.Merge(m, src)
+}
+func (m *HasRoleResp) XXX_Size() int {
+	return xxx_messageInfo_HasRoleResp.Size(m)
+}
+func (m *HasRoleResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_HasRoleResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HasRoleResp proto.InternalMessageInfo
+
+func (m *HasRoleResp) GetRes() bool {
+	if m != nil {
+		return m.Res
+	}
+	return false
+}
+
+type StringMessage struct {
+	Value                string   `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StringMessage) Reset()         { *m = StringMessage{} }
+func (m *StringMessage) String() string { return proto.CompactTextString(m) }
+func (*StringMessage) ProtoMessage()    {}
+func (*StringMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{12}
+}
+
+func (m *StringMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StringMessage.Unmarshal(m, b)
+}
+func (m *StringMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StringMessage.Marshal(b, m, deterministic)
+}
+func (m *StringMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StringMessage.Merge(m, src)
+}
+func (m *StringMessage) XXX_Size() int {
+	return xxx_messageInfo_StringMessage.Size(m)
+}
+func (m *StringMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_StringMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StringMessage proto.InternalMessageInfo
+
+func (m *StringMessage) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type Policy struct {
+	Params               []string `protobuf:"bytes,1,rep,name=params,proto3" json:"params,omitempty"`
+	Dom                  string   `protobuf:"bytes,2,opt,name=dom,proto3" json:"dom,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Policy) Reset()         { *m = Policy{} }
+func (m *Policy) String() string { return proto.CompactTextString(m) }
+func (*Policy) ProtoMessage()    {}
+func (*Policy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{13}
+}
+
+func (m *Policy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Policy.Unmarshal(m, b)
+}
+func (m *Policy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Policy.Marshal(b, m, deterministic)
+}
+func (m *Policy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Policy.Merge(m, src)
+}
+func (m *Policy) XXX_Size() int {
+	return xxx_messageInfo_Policy.Size(m)
+}
+func (m *Policy) XXX_DiscardUnknown() {
+	xxx_messageInfo_Policy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Policy proto.InternalMessageInfo
+
+func (m *Policy) GetParams() []string {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
+func (m *Policy) GetDom() string {
+	if m != nil {
+		return m.Dom
+	}
+	return ""
+}
+
+type PolicyResp struct {
+	Res                  bool     `protobuf:"varint,1,opt,name=res,proto3" json:"res,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PolicyResp) Reset()         { *m = PolicyResp{} }
+func (m *PolicyResp) String() string { return proto.CompactTextString(m) }
+func (*PolicyResp) ProtoMessage()    {}
+func (*PolicyResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{14}
+}
+
+func (m *PolicyResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PolicyResp.Unmarshal(m, b)
+}
+func (m *PolicyResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PolicyResp.Marshal(b, m, deterministic)
+}
+func (m *PolicyResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PolicyResp.Merge(m, src)
+}
+func (m *PolicyResp) XXX_Size() int {
+	return xxx_messageInfo_PolicyResp.Size(m)
+}
+func (m *PolicyResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_PolicyResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PolicyResp proto.InternalMessageInfo
+
+func (m *PolicyResp) GetRes() bool {
+	if m != nil {
+		return m.Res
+	}
+	return false
+}
+
+type PolicyFilter struct {
+	FieldIndex           int32    `protobuf:"varint,1,opt,name=field_index,json=fieldIndex,proto3" json:"field_index,omitempty"`
+	FieldValues          []string `protobuf:"bytes,2,rep,name=field_values,json=fieldValues,proto3" json:"field_values,omitempty"`
+	Dom                  string   `protobuf:"bytes,3,opt,name=dom,proto3" json:"dom,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PolicyFilter) Reset()         { *m = PolicyFilter{} }
+func (m *PolicyFilter) String() string { return proto.CompactTextString(m) }
+func (*PolicyFilter) ProtoMessage()    {}
+func (*PolicyFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{15}
+}
+
+func (m *PolicyFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PolicyFilter.Unmarshal(m, b)
+}
+func (m *PolicyFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PolicyFilter.Marshal(b, m, deterministic)
+}
+func (m *PolicyFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PolicyFilter.Merge(m, src)
+}
+func (m *PolicyFilter) XXX_Size() int {
+	return xxx_messageInfo_PolicyFilter.Size(m)
+}
+func (m *PolicyFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_PolicyFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PolicyFilter proto.InternalMessageInfo
+
+func (m *PolicyFilter) GetFieldIndex() int32 {
+	if m != nil {
+		return m.FieldIndex
+	}
+	return 0
+}
+
+func (m *PolicyFilter) GetFieldValues() []string {
+	if m != nil {
+		return m.FieldValues
+	}
+	return nil
+}
+
+func (m *PolicyFilter) GetDom() string {
+	if m != nil {
+		return m.Dom
+	}
+	return ""
+}
+
+// policy_version numbers the live policy like AccessControlResp.policy_version;
+// it starts again at 1 when the server restarts. The persistent revisions
+// of the policy history are numbered separately, see PolicyRevision.
+type PolicyList struct {
+	Policies             []*Policy `protobuf:"bytes,1,rep,name=policies,proto3" json:"policies,omitempty"`
+	PolicyVersion        uint64    `protobuf:"varint,2,opt,name=policy_version,json=policyVersion,proto3" json:"policy_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *PolicyList) Reset()         { *m = PolicyList{} }
+func (m *PolicyList) String() string { return proto.CompactTextString(m) }
+func (*PolicyList) ProtoMessage()    {}
+func (*PolicyList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{16}
+}
+
+func (m *PolicyList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PolicyList.Unmarshal(m, b)
+}
+func (m *PolicyList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PolicyList.Marshal(b, m, deterministic)
+}
+func (m *PolicyList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PolicyList.Merge(m, src)
+}
+func (m *PolicyList) XXX_Size() int {
+	return xxx_messageInfo_PolicyList.Size(m)
+}
+func (m *PolicyList) XXX_DiscardUnknown() {
+	xxx_messageInfo_PolicyList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PolicyList proto.InternalMessageInfo
+
+func (m *PolicyList) GetPolicies() []*Policy {
+	if m != nil {
+		return m.Policies
+	}
+	return nil
+}
+
+func (m *PolicyList) GetPolicyVersion() uint64 {
+	if m != nil {
+		return m.PolicyVersion
+	}
+	return 0
+}
+
+type WatchPolicyReq struct {
+	FromPolicyVersion    uint64   `protobuf:"varint,1,opt,name=from_policy_version,json=fromPolicyVersion,proto3" json:"from_policy_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchPolicyReq) Reset()         { *m = WatchPolicyReq{} }
+func (m *WatchPolicyReq) String() string { return proto.CompactTextString(m) }
+func (*WatchPolicyReq) ProtoMessage()    {}
+func (*WatchPolicyReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{17}
+}
+
+func (m *WatchPolicyReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchPolicyReq.Unmarshal(m, b)
+}
+func (m *WatchPolicyReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchPolicyReq.Marshal(b, m, deterministic)
+}
+func (m *WatchPolicyReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchPolicyReq.Merge(m, src)
+}
+func (m *WatchPolicyReq) XXX_Size() int {
+	return xxx_messageInfo_WatchPolicyReq.Size(m)
+}
+func (m *WatchPolicyReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchPolicyReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchPolicyReq proto.InternalMessageInfo
+
+func (m *WatchPolicyReq) GetFromPolicyVersion() uint64 {
+	if m != nil {
+		return m.FromPolicyVersion
+	}
+	return 0
+}
+
+type PolicyEvent struct {
+	PolicyVersion        uint64           `protobuf:"varint,1,opt,name=policy_version,json=policyVersion,proto3" json:"policy_version,omitempty"`
+	Type                 PolicyEvent_Type `protobuf:"varint,2,opt,name=type,proto3,enum=PolicyEvent_Type" json:"type,omitempty"`
+	Sec                  string           `protobuf:"bytes,3,opt,name=sec,proto3" json:"sec,omitempty"`
+	Policy               *Policy          `protobuf:"bytes,4,opt,name=policy,proto3" json:"policy,omitempty"`
+	Timestamp            int64            `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *PolicyEvent) Reset()         { *m = PolicyEvent{} }
+func (m *PolicyEvent) String() string { return proto.CompactTextString(m) }
+func (*PolicyEvent) ProtoMessage()    {}
+func (*PolicyEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{18}
+}
+
+func (m *PolicyEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PolicyEvent.Unmarshal(m, b)
+}
+func (m *PolicyEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PolicyEvent.Marshal(b, m, deterministic)
+}
+func (m *PolicyEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PolicyEvent.Merge(m, src)
+}
+func (m *PolicyEvent) XXX_Size() int {
+	return xxx_messageInfo_PolicyEvent.Size(m)
+}
+func (m *PolicyEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_PolicyEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PolicyEvent proto.InternalMessageInfo
+
+func (m *PolicyEvent) GetPolicyVersion() uint64 {
+	if m != nil {
+		return m.PolicyVersion
+	}
+	return 0
+}
+
+func (m *PolicyEvent) GetType() PolicyEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return PolicyEvent_RELOAD
+}
+
+func (m *PolicyEvent) GetSec() string {
+	if m != nil {
+		return m.Sec
+	}
+	return ""
+}
+
+func (m *PolicyEvent) GetPolicy() *Policy {
+	if m != nil {
+		return m.Policy
+	}
+	return nil
+}
+
+func (m *PolicyEvent) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type SnapshotReq struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SnapshotReq) Reset()         { *m = SnapshotReq{} }
+func (m *SnapshotReq) String() string { return proto.CompactTextString(m) }
+func (*SnapshotReq) ProtoMessage()    {}
+func (*SnapshotReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{19}
+}
+
+func (m *SnapshotReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotReq.Unmarshal(m, b)
+}
+func (m *SnapshotReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotReq.Marshal(b, m, deterministic)
+}
+func (m *SnapshotReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotReq.Merge(m, src)
+}
+func (m *SnapshotReq) XXX_Size() int {
+	return xxx_messageInfo_SnapshotReq.Size(m)
+}
+func (m *SnapshotReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotReq proto.InternalMessageInfo
+
+type PolicySnapshot struct {
+	Policies             []*Policy `protobuf:"bytes,1,rep,name=policies,proto3" json:"policies,omitempty"`
+	GroupingPolicies     []*Policy `protobuf:"bytes,2,rep,name=grouping_policies,json=groupingPolicies,proto3" json:"grouping_policies,omitempty"`
+	PolicyVersion        uint64    `protobuf:"varint,3,opt,name=policy_version,json=policyVersion,proto3" json:"policy_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *PolicySnapshot) Reset()         { *m = PolicySnapshot{} }
+func (m *PolicySnapshot) String() string { return proto.CompactTextString(m) }
+func (*PolicySnapshot) ProtoMessage()    {}
+func (*PolicySnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{20}
+}
+
+func (m *PolicySnapshot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PolicySnapshot.Unmarshal(m, b)
+}
+func (m *PolicySnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PolicySnapshot.Marshal(b, m, deterministic)
+}
+func (m *PolicySnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PolicySnapshot.Merge(m, src)
+}
+func (m *PolicySnapshot) XXX_Size() int {
+	return xxx_messageInfo_PolicySnapshot.Size(m)
+}
+func (m *PolicySnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_PolicySnapshot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PolicySnapshot proto.InternalMessageInfo
+
+func (m *PolicySnapshot) GetPolicies() []*Policy {
+	if m != nil {
+		return m.Policies
+	}
+	return nil
+}
+
+func (m *PolicySnapshot) GetGroupingPolicies() []*Policy {
+	if m != nil {
+		return m.GroupingPolicies
+	}
+	return nil
+}
+
+func (m *PolicySnapshot) GetPolicyVersion() uint64 {
+	if m != nil {
+		return m.PolicyVersion
+	}
+	return 0
+}
+
+type RuleChange struct {
+	Sec                  string   `protobuf:"bytes,1,opt,name=sec,proto3" json:"sec,omitempty"`
+	Policy               *Policy  `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RuleChange) Reset()         { *m = RuleChange{} }
+func (m *RuleChange) String() string { return proto.CompactTextString(m) }
+func (*RuleChange) ProtoMessage()    {}
+func (*RuleChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{21}
+}
+
+func (m *RuleChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RuleChange.Unmarshal(m, b)
+}
+func (m *RuleChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RuleChange.Marshal(b, m, deterministic)
+}
+func (m *RuleChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RuleChange.Merge(m, src)
+}
+func (m *RuleChange) XXX_Size() int {
+	return xxx_messageInfo_RuleChange.Size(m)
+}
+func (m *RuleChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_RuleChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RuleChange proto.InternalMessageInfo
+
+func (m *RuleChange) GetSec() string {
+	if m != nil {
+		return m.Sec
+	}
+	return ""
+}
+
+func (m *RuleChange) GetPolicy() *Policy {
+	if m != nil {
+		return m.Policy
+	}
+	return nil
+}
+
+type PolicyRevision struct {
+	Revision             uint64              `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	Timestamp            int64               `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Author               string              `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Kind                 PolicyRevision_Kind `protobuf:"varint,4,opt,name=kind,proto3,enum=PolicyRevision_Kind" json:"kind,omitempty"`
+	RollbackTo           uint64              `protobuf:"varint,5,opt,name=rollback_to,json=rollbackTo,proto3" json:"rollback_to,omitempty"`
+	Added                []*RuleChange       `protobuf:"bytes,6,rep,name=added,proto3" json:"added,omitempty"`
+	Removed              []*RuleChange       `protobuf:"bytes,7,rep,name=removed,proto3" json:"removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *PolicyRevision) Reset()         { *m = PolicyRevision{} }
+func (m *PolicyRevision) String() string { return proto.CompactTextString(m) }
+func (*PolicyRevision) ProtoMessage()    {}
+func (*PolicyRevision) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{22}
+}
+
+func (m *PolicyRevision) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PolicyRevision.Unmarshal(m, b)
+}
+func (m *PolicyRevision) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PolicyRevision.Marshal(b, m, deterministic)
+}
+func (m *PolicyRevision) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PolicyRevision.Merge(m, src)
+}
+func (m *PolicyRevision) XXX_Size() int {
+	return xxx_messageInfo_PolicyRevision.Size(m)
+}
+func (m *PolicyRevision) XXX_DiscardUnknown() {
+	xxx_messageInfo_PolicyRevision.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PolicyRevision proto.InternalMessageInfo
+
+func (m *PolicyRevision) GetRevision() uint64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *PolicyRevision) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *PolicyRevision) GetAuthor() string {
+	if m != nil {
+		return m.Author
+	}
+	return ""
+}
+
+func (m *PolicyRevision) GetKind() PolicyRevision_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return PolicyRevision_LOAD
+}
+
+func (m *PolicyRevision) GetRollbackTo() uint64 {
+	if m != nil {
+		return m.RollbackTo
+	}
+	return 0
+}
+
+func (m *PolicyRevision) GetAdded() []*RuleChange {
+	if m != nil {
+		return m.Added
+	}
+	return nil
+}
+
+func (m *PolicyRevision) GetRemoved() []*RuleChange {
+	if m != nil {
+		return m.Removed
+	}
+	return nil
+}
+
+type RevisionFilter struct {
+	Limit                uint32   `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevisionFilter) Reset()         { *m = RevisionFilter{} }
+func (m *RevisionFilter) String() string { return proto.CompactTextString(m) }
+func (*RevisionFilter) ProtoMessage()    {}
+func (*RevisionFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{23}
+}
+
+func (m *RevisionFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevisionFilter.Unmarshal(m, b)
+}
+func (m *RevisionFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevisionFilter.Marshal(b, m, deterministic)
+}
+func (m *RevisionFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevisionFilter.Merge(m, src)
+}
+func (m *RevisionFilter) XXX_Size() int {
+	return xxx_messageInfo_RevisionFilter.Size(m)
+}
+func (m *RevisionFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevisionFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevisionFilter proto.InternalMessageInfo
+
+func (m *RevisionFilter) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type RevisionList struct {
+	Revisions            []*PolicyRevision `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *RevisionList) Reset()         { *m = RevisionList{} }
+func (m *RevisionList) String() string { return proto.CompactTextString(m) }
+func (*RevisionList) ProtoMessage()    {}
+func (*RevisionList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{24}
+}
+
+func (m *RevisionList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevisionList.Unmarshal(m, b)
+}
+func (m *RevisionList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevisionList.Marshal(b, m, deterministic)
+}
+func (m *RevisionList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevisionList.Merge(m, src)
+}
+func (m *RevisionList) XXX_Size() int {
+	return xxx_messageInfo_RevisionList.Size(m)
+}
+func (m *RevisionList) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevisionList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevisionList proto.InternalMessageInfo
+
+func (m *RevisionList) GetRevisions() []*PolicyRevision {
+	if m != nil {
+		return m.Revisions
+	}
+	return nil
+}
+
+type RevisionReq struct {
+	Revision             uint64   `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevisionReq) Reset()         { *m = RevisionReq{} }
+func (m *RevisionReq) String() string { return proto.CompactTextString(m) }
+func (*RevisionReq) ProtoMessage()    {}
+func (*RevisionReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{25}
+}
+
+func (m *RevisionReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevisionReq.Unmarshal(m, b)
+}
+func (m *RevisionReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevisionReq.Marshal(b, m, deterministic)
+}
+func (m *RevisionReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevisionReq.Merge(m, src)
+}
+func (m *RevisionReq) XXX_Size() int {
+	return xxx_messageInfo_RevisionReq.Size(m)
+}
+func (m *RevisionReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevisionReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevisionReq proto.InternalMessageInfo
+
+func (m *RevisionReq) GetRevision() uint64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+type RollbackResp struct {
+	Revision             *PolicyRevision `protobuf:"bytes,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	PolicyVersion        uint64          `protobuf:"varint,2,opt,name=policy_version,json=policyVersion,proto3" json:"policy_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *RollbackResp) Reset()         { *m = RollbackResp{} }
+func (m *RollbackResp) String() string { return proto.CompactTextString(m) }
+func (*RollbackResp) ProtoMessage()    {}
+func (*RollbackResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{26}
+}
+
+func (m *RollbackResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RollbackResp.Unmarshal(m, b)
+}
+func (m *RollbackResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RollbackResp.Marshal(b, m, deterministic)
+}
+func (m *RollbackResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RollbackResp.Merge(m, src)
+}
+func (m *RollbackResp) XXX_Size() int {
+	return xxx_messageInfo_RollbackResp.Size(m)
+}
+func (m *RollbackResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_RollbackResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RollbackResp proto.InternalMessageInfo
+
+func (m *RollbackResp) GetRevision() *PolicyRevision {
+	if m != nil {
+		return m.Revision
+	}
+	return nil
+}
+
+func (m *RollbackResp) GetPolicyVersion() uint64 {
+	if m != nil {
+		return m.PolicyVersion
+	}
+	return 0
+}
+
+type SimulateReq struct {
+	Add                  []*RuleChange `protobuf:"bytes,1,rep,name=add,proto3" json:"add,omitempty"`
+	Remove               []*RuleChange `protobuf:"bytes,2,rep,name=remove,proto3" json:"remove,omitempty"`
+	Replay               uint32        `protobuf:"varint,3,opt,name=replay,proto3" json:"replay,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *SimulateReq) Reset()         { *m = SimulateReq{} }
+func (m *SimulateReq) String() string { return proto.CompactTextString(m) }
+func (*SimulateReq) ProtoMessage()    {}
+func (*SimulateReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{27}
+}
+
+func (m *SimulateReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SimulateReq.Unmarshal(m, b)
+}
+func (m *SimulateReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SimulateReq.Marshal(b, m, deterministic)
+}
+func (m *SimulateReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SimulateReq.Merge(m, src)
+}
+func (m *SimulateReq) XXX_Size() int {
+	return xxx_messageInfo_SimulateReq.Size(m)
+}
+func (m *SimulateReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SimulateReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SimulateReq proto.InternalMessageInfo
+
+func (m *SimulateReq) GetAdd() []*RuleChange {
+	if m != nil {
+		return m.Add
+	}
+	return nil
+}
+
+func (m *SimulateReq) GetRemove() []*RuleChange {
+	if m != nil {
+		return m.Remove
+	}
+	return nil
+}
+
+func (m *SimulateReq) GetReplay() uint32 {
+	if m != nil {
+		return m.Replay
+	}
+	return 0
+}
+
+type DecisionChange struct {
+	Req                  *AccessControlReq `protobuf:"bytes,1,opt,name=req,proto3" json:"req,omitempty"`
+	Before               bool              `protobuf:"varint,2,opt,name=before,proto3" json:"before,omitempty"`
+	After                bool              `protobuf:"varint,3,opt,name=after,proto3" json:"after,omitempty"`
+	Replayed             bool              `protobuf:"varint,4,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *DecisionChange) Reset()         { *m = DecisionChange{} }
+func (m *DecisionChange) String() string { return proto.CompactTextString(m) }
+func (*DecisionChange) ProtoMessage()    {}
+func (*DecisionChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{28}
+}
+
+func (m *DecisionChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecisionChange.Unmarshal(m, b)
+}
+func (m *DecisionChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecisionChange.Marshal(b, m, deterministic)
+}
+func (m *DecisionChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecisionChange.Merge(m, src)
+}
+func (m *DecisionChange) XXX_Size() int {
+	return xxx_messageInfo_DecisionChange.Size(m)
+}
+func (m *DecisionChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecisionChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecisionChange proto.InternalMessageInfo
+
+func (m *DecisionChange) GetReq() *AccessControlReq {
+	if m != nil {
+		return m.Req
+	}
+	return nil
+}
+
+func (m *DecisionChange) GetBefore() bool {
+	if m != nil {
+		return m.Before
+	}
+	return false
+}
+
+func (m *DecisionChange) GetAfter() bool {
+	if m != nil {
+		return m.After
+	}
+	return false
+}
+
+func (m *DecisionChange) GetReplayed() bool {
+	if m != nil {
+		return m.Replayed
+	}
+	return false
+}
+
+type SimulateResp struct {
+	Changes              []*DecisionChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	PolicyVersion        uint64            `protobuf:"varint,2,opt,name=policy_version,json=policyVersion,proto3" json:"policy_version,omitempty"`
+	Evaluated            uint32            `protobuf:"varint,3,opt,name=evaluated,proto3" json:"evaluated,omitempty"`
+	Replayed             uint32            `protobuf:"varint,4,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	Truncated            bool              `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SimulateResp) Reset()         { *m = SimulateResp{} }
+func (m *SimulateResp) String() string { return proto.CompactTextString(m) }
+func (*SimulateResp) ProtoMessage()    {}
+func (*SimulateResp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fe87c01588b1ab0e, []int{29}
+}
+
+func (m *SimulateResp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SimulateResp.Unmarshal(m, b)
+}
+func (m *SimulateResp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SimulateResp.Marshal(b, m, deterministic)
+}
+func (m *SimulateResp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SimulateResp.Merge(m, src)
+}
+func (m *SimulateResp) XXX_Size() int {
+	return xxx_messageInfo_SimulateResp.Size(m)
+}
+func (m *SimulateResp) XXX_DiscardUnknown() {
+	xxx_messageInfo_SimulateResp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SimulateResp proto.InternalMessageInfo
+
+func (m *SimulateResp) GetChanges() []*DecisionChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+func (m *SimulateResp) GetPolicyVersion() uint64 {
+	if m != nil {
+		return m.PolicyVersion
+	}
+	return 0
+}
+
+func (m *SimulateResp) GetEvaluated() uint32 {
+	if m != nil {
+		return m.Evaluated
+	}
+	return 0
+}
+
+func (m *SimulateResp) GetReplayed() uint32 {
+	if m != nil {
+		return m.Replayed
+	}
+	return 0
+}
+
+func (m *SimulateResp) GetTruncated() bool {
+	if m != nil {
+		return m.Truncated
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterEnum("PolicyEvent_Type", PolicyEvent_Type_name, PolicyEvent_Type_value)
+	proto.RegisterEnum("PolicyRevision_Kind", PolicyRevision_Kind_name, PolicyRevision_Kind_value)
+	proto.RegisterType((*AttributeValue)(nil), "AttributeValue")
+	proto.RegisterType((*AccessControlReq)(nil), "AccessControlReq")
+	proto.RegisterMapType((map[string]*AttributeValue)(nil), "AccessControlReq.EnvAttrsEntry")
+	proto.RegisterMapType((map[string]*AttributeValue)(nil), "AccessControlReq.ObjAttrsEntry")
+	proto.RegisterMapType((map[string]*AttributeValue)(nil), "AccessControlReq.SubAttrsEntry")
+	proto.RegisterType((*AccessControlResp)(nil), "AccessControlResp")
+	proto.RegisterType((*MatchedRule)(nil), "MatchedRule")
+	proto.RegisterType((*ExplainResp)(nil), "ExplainResp")
+	proto.RegisterType((*BatchCheckReq)(nil), "BatchCheckReq")
+	proto.RegisterType((*BatchCheckResp)(nil), "BatchCheckResp")
+	proto.RegisterType((*WhoCanReq)(nil), "WhoCanReq")
+	proto.RegisterType((*WhoCanResp)(nil), "WhoCanResp")
+	proto.RegisterType((*RoleReq)(nil), "RoleReq")
+	proto.RegisterType((*RoleList)(nil), "RoleList")
+	proto.RegisterType((*HasRoleResp)(nil), "HasRoleResp")
+	proto.RegisterType((*StringMessage)(nil), "StringMessage")
+	proto.RegisterType((*Policy)(nil), "Policy")
+	proto.RegisterType((*PolicyResp)(nil), "PolicyResp")
+	proto.RegisterType((*PolicyFilter)(nil), "PolicyFilter")
+	proto.RegisterType((*PolicyList)(nil), "PolicyList")
+	proto.RegisterType((*WatchPolicyReq)(nil), "WatchPolicyReq")
+	proto.RegisterType((*PolicyEvent)(nil), "PolicyEvent")
+	proto.RegisterType((*SnapshotReq)(nil), "SnapshotReq")
+	proto.RegisterType((*PolicySnapshot)(nil), "PolicySnapshot")
+	proto.RegisterType((*RuleChange)(nil), "RuleChange")
+	proto.RegisterType((*PolicyRevision)(nil), "PolicyRevision")
+	proto.RegisterType((*RevisionFilter)(nil), "RevisionFilter")
+	proto.RegisterType((*RevisionList)(nil), "RevisionList")
+	proto.RegisterType((*RevisionReq)(nil), "RevisionReq")
+	proto.RegisterType((*RollbackResp)(nil), "RollbackResp")
+	proto.RegisterType((*SimulateReq)(nil), "SimulateReq")
+	proto.RegisterType((*DecisionChange)(nil), "DecisionChange")
+	proto.RegisterType((*SimulateResp)(nil), "SimulateResp")
+}
+
+func init() { proto.RegisterFile("proto/access_control.proto", fileDescriptor_fe87c01588b1ab0e) }
+
+var fileDescriptor_fe87c01588b1ab0e = []byte{
+	// 1656 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x58, 0xdd, 0x6e, 0xe3, 0xc6,
+	0x15, 0x5e, 0xea, 0xcf, 0xd2, 0xd1, 0x8f, 0xe5, 0x89, 0xdb, 0x0a, 0x6c, 0x52, 0x7b, 0xb9, 0x71,
+	0xea, 0x4d, 0x9b, 0x71, 0xeb, 0xb4, 0x41, 0x10, 0xb4, 0x68, 0xbd, 0xb6, 0xe3, 0x5d, 0xc4, 0x8b,
+	0x35, 0x66, 0xb7, 0x9b, 0xde, 0x19, 0x14, 0x39, 0xb2, 0x68, 0x53, 0x1c, 0x9a, 0x1c, 0x09, 0xeb,
+	0xab, 0x02, 0xe9, 0x1b, 0xb4, 0x37, 0xbd, 0xed, 0x5b, 0x14, 0x7d, 0x8d, 0xa2, 0x6f, 0xd0, 0xe7,
+	0x28, 0x82, 0x33, 0x33, 0xa4, 0x48, 0x89, 0x5e, 0x18, 0xc8, 0x95, 0x79, 0xce, 0x7c, 0xe7, 0x67,
+	0xce, 0xdf, 0x1c, 0x0b, 0xec, 0x38, 0x11, 0x52, 0x1c, 0xb8, 0x9e, 0xc7, 0xd3, 0xf4, 0xd2, 0x13,
+	0x91, 0x4c, 0x44, 0x48, 0x15, 0xd3, 0xfe, 0xf0, 0x4a, 0x88, 0xab, 0x90, 0x1f, 0xb8, 0x71, 0x70,
+	0xe0, 0x46, 0x91, 0x90, 0xae, 0x0c, 0x44, 0x94, 0xea, 0x53, 0xe7, 0xaf, 0x16, 0x0c, 0x8e, 0xa4,
+	0x4c, 0x82, 0xf1, 0x5c, 0xf2, 0xb7, 0x6e, 0x38, 0xe7, 0xe4, 0x09, 0xf4, 0x52, 0x99, 0x04, 0xd1,
+	0xd5, 0xe5, 0x02, 0xe9, 0x91, 0xb5, 0x6b, 0xed, 0x77, 0x9e, 0x3f, 0x62, 0x5d, 0xcd, 0xcd, 0x41,
+	0xd1, 0x7c, 0x36, 0xe6, 0x89, 0x01, 0xd5, 0x76, 0xad, 0x7d, 0x0b, 0x41, 0x9a, 0xab, 0x41, 0x3b,
+	0x00, 0x63, 0x21, 0x42, 0x03, 0xa9, 0xef, 0x5a, 0xfb, 0xed, 0xe7, 0x8f, 0x58, 0x07, 0x79, 0x0a,
+	0xf0, 0xac, 0x05, 0x8d, 0x9b, 0x20, 0xf2, 0x9d, 0xff, 0xd7, 0x61, 0x78, 0xa4, 0x9c, 0x3f, 0xd6,
+	0xbe, 0x33, 0x7e, 0x4b, 0x86, 0x50, 0x4f, 0xe7, 0x63, 0x6d, 0x9e, 0xe1, 0x27, 0x72, 0xc4, 0xf8,
+	0x5a, 0xd9, 0xea, 0x30, 0xfc, 0x44, 0x8e, 0xeb, 0x49, 0xa5, 0xba, 0xc3, 0xf0, 0x13, 0x39, 0xbe,
+	0x98, 0x8d, 0x1a, 0x9a, 0xe3, 0x8b, 0x19, 0xf9, 0x1d, 0x74, 0xd2, 0xf9, 0xf8, 0xd2, 0x95, 0x32,
+	0x49, 0x47, 0xcd, 0xdd, 0xfa, 0x7e, 0xf7, 0x70, 0x87, 0xae, 0x5a, 0xa3, 0xaf, 0xe7, 0x63, 0x8c,
+	0x43, 0x7a, 0x1a, 0xc9, 0xe4, 0x8e, 0xb5, 0x53, 0x43, 0xa2, 0xb4, 0x18, 0x5f, 0x1b, 0xe9, 0xd6,
+	0x7d, 0xd2, 0xaf, 0xc6, 0xd7, 0x45, 0x69, 0x31, 0xbe, 0xce, 0xa5, 0x79, 0xb4, 0x30, 0xd2, 0x1b,
+	0xf7, 0x49, 0x9f, 0x46, 0x8b, 0xa2, 0x34, 0x37, 0xa4, 0x7d, 0x0e, 0xfd, 0x92, 0x5b, 0x78, 0xb9,
+	0x1b, 0x7e, 0x97, 0x85, 0xe4, 0x86, 0xdf, 0x91, 0x3d, 0x68, 0x2e, 0x13, 0xd0, 0x3d, 0xdc, 0xa4,
+	0xe5, 0x64, 0x32, 0x7d, 0xfa, 0x55, 0xed, 0x4b, 0x0b, 0xb5, 0x95, 0xdc, 0xfc, 0xc1, 0xda, 0x4a,
+	0x6e, 0xff, 0x20, 0x6d, 0xce, 0x39, 0x6c, 0xad, 0x44, 0x25, 0x8d, 0x51, 0x63, 0xc2, 0x53, 0xa5,
+	0xb1, 0xcd, 0xf0, 0x93, 0xec, 0xc1, 0x20, 0x16, 0x61, 0xe0, 0xdd, 0x5d, 0x2e, 0x78, 0x92, 0x06,
+	0x22, 0x52, 0xaa, 0x1b, 0xac, 0xaf, 0xb9, 0x6f, 0x35, 0xd3, 0xf9, 0x87, 0x05, 0xdd, 0x97, 0xae,
+	0xf4, 0xa6, 0xdc, 0x67, 0xf3, 0x90, 0x93, 0x6d, 0x68, 0x06, 0x91, 0xcf, 0xdf, 0x29, 0x55, 0x4d,
+	0xa6, 0x09, 0xb2, 0x03, 0x2d, 0x2d, 0x66, 0xfc, 0xdb, 0xa0, 0x17, 0x8a, 0x64, 0x86, 0x8d, 0xf6,
+	0xf9, 0x24, 0x2f, 0x2e, 0x3e, 0x91, 0xe4, 0x63, 0xe8, 0x24, 0x22, 0xe4, 0x97, 0xb1, 0x2b, 0xa7,
+	0xa3, 0xc6, 0x6e, 0xbd, 0x28, 0xd5, 0xc6, 0x93, 0x0b, 0x57, 0x4e, 0x89, 0x0d, 0x6d, 0x9f, 0x7b,
+	0x41, 0x1a, 0x2c, 0xf8, 0xa8, 0xa9, 0x9c, 0xcf, 0x69, 0xe7, 0x3b, 0x0b, 0xba, 0xa7, 0xef, 0xe2,
+	0xd0, 0x0d, 0xa2, 0x7b, 0xee, 0xf8, 0x63, 0x68, 0xf1, 0xc9, 0x84, 0x7b, 0xd2, 0xd4, 0xb9, 0xa1,
+	0x88, 0x03, 0xcd, 0x64, 0x1e, 0xf2, 0x74, 0x54, 0x57, 0x76, 0x7b, 0xb4, 0x70, 0x43, 0xa6, 0x8f,
+	0x2a, 0xe2, 0xd3, 0xa8, 0x8a, 0xcf, 0x17, 0xd0, 0x7f, 0x86, 0xc2, 0xc7, 0x53, 0xee, 0xdd, 0x60,
+	0xab, 0xed, 0x41, 0x23, 0xe1, 0xb7, 0xe8, 0x06, 0xaa, 0xde, 0x5a, 0xab, 0x50, 0xa6, 0x8e, 0x1d,
+	0x17, 0x06, 0x45, 0xb9, 0x34, 0x26, 0xfb, 0xd0, 0x4c, 0x78, 0x1a, 0x67, 0x92, 0x84, 0xae, 0x65,
+	0x91, 0x69, 0xc0, 0x43, 0x53, 0x77, 0x04, 0x9d, 0x6f, 0xa7, 0xe2, 0xd8, 0x8d, 0xcc, 0x04, 0xc0,
+	0x7e, 0xb7, 0xd6, 0xfa, 0xbd, 0xb6, 0xd6, 0xef, 0xf5, 0xbc, 0xdf, 0x9d, 0x2f, 0x01, 0x32, 0x15,
+	0x69, 0x8c, 0xb9, 0x9f, 0xa7, 0x3c, 0xd1, 0x1e, 0x76, 0x98, 0x26, 0x90, 0x8b, 0xe9, 0x4a, 0x47,
+	0x35, 0xcd, 0x55, 0x84, 0x73, 0x0c, 0x1b, 0x4c, 0x84, 0x1c, 0x4d, 0x13, 0x68, 0x20, 0xd2, 0xd8,
+	0x56, 0xdf, 0xc8, 0x43, 0x9c, 0xb1, 0xae, 0xbe, 0x2b, 0xcc, 0xef, 0x42, 0x1b, 0x95, 0x9c, 0x07,
+	0xa9, 0x44, 0x33, 0x91, 0x3b, 0xe3, 0xb9, 0x71, 0x45, 0x38, 0x3b, 0xd0, 0x7d, 0xee, 0xa6, 0xda,
+	0x52, 0x55, 0x09, 0x38, 0x7b, 0xd0, 0x7f, 0xad, 0x66, 0xed, 0x4b, 0x9e, 0xa6, 0xee, 0x95, 0x2a,
+	0xe0, 0xc2, 0x2c, 0x36, 0x8d, 0xe3, 0x1c, 0x42, 0x4b, 0xd7, 0x1e, 0xd6, 0x4c, 0xec, 0x26, 0xee,
+	0x2c, 0x33, 0x64, 0xa8, 0xcc, 0xbb, 0xda, 0xd2, 0xbb, 0x9f, 0x01, 0x98, 0x7a, 0xad, 0x36, 0xed,
+	0x43, 0x4f, 0x9f, 0x7f, 0x1d, 0x84, 0x92, 0x27, 0x64, 0x07, 0xba, 0x93, 0x80, 0x87, 0xfe, 0x65,
+	0xb1, 0x81, 0x40, 0xb1, 0x5e, 0x20, 0x87, 0x3c, 0x86, 0x9e, 0x06, 0x28, 0x9f, 0xb2, 0x80, 0x6a,
+	0x21, 0xd5, 0xe6, 0x69, 0x45, 0x8c, 0xfe, 0x9c, 0x79, 0xa1, 0xa2, 0xf4, 0x04, 0xda, 0xaa, 0x08,
+	0x02, 0x9e, 0xd5, 0xd1, 0xb2, 0xa9, 0xb2, 0x83, 0x87, 0xd6, 0xcf, 0x1f, 0x61, 0xf0, 0x2d, 0x96,
+	0x68, 0x76, 0xc9, 0x5b, 0x42, 0xe1, 0x83, 0x49, 0x22, 0x66, 0x97, 0x2b, 0xd2, 0x96, 0x92, 0xde,
+	0xc2, 0xa3, 0x8b, 0x92, 0x86, 0xff, 0x5a, 0xd0, 0xd5, 0x9c, 0xd3, 0x05, 0x8f, 0x64, 0x85, 0x61,
+	0xab, 0xc2, 0x30, 0xb6, 0x90, 0xbc, 0x8b, 0x75, 0x71, 0x0c, 0x0e, 0xb7, 0x68, 0x41, 0x05, 0x7d,
+	0x73, 0x17, 0x73, 0xa6, 0x8e, 0xd5, 0xa3, 0xc6, 0xbd, 0x2c, 0x16, 0x29, 0xf7, 0x0a, 0x63, 0xa8,
+	0x51, 0x3d, 0x86, 0x3e, 0x84, 0x8e, 0x0c, 0x66, 0x3c, 0x95, 0xee, 0x2c, 0x56, 0xf3, 0xa4, 0xce,
+	0x96, 0x0c, 0xe7, 0xe7, 0xd0, 0x40, 0xf5, 0x04, 0xa0, 0xc5, 0x4e, 0xcf, 0x5f, 0x1d, 0x9d, 0x0c,
+	0x1f, 0x91, 0x0d, 0xa8, 0x1f, 0x9d, 0x9c, 0x0c, 0x2d, 0xcd, 0x7c, 0xf9, 0xea, 0xed, 0xe9, 0xb0,
+	0xe6, 0xf4, 0xa1, 0xfb, 0x3a, 0x72, 0xe3, 0x74, 0x2a, 0x24, 0xe3, 0xb7, 0xce, 0xdf, 0x2c, 0x18,
+	0x68, 0x43, 0x19, 0xf7, 0x61, 0x79, 0xf8, 0x0d, 0x6c, 0x5d, 0x25, 0x62, 0x1e, 0xe3, 0x7e, 0x90,
+	0xa3, 0x6b, 0x65, 0xf4, 0x30, 0x43, 0x5c, 0xdc, 0x9f, 0xbd, 0x7a, 0x55, 0xf6, 0xfe, 0x00, 0x80,
+	0xe3, 0xec, 0x78, 0xea, 0x46, 0x57, 0x79, 0xac, 0xac, 0xaa, 0x58, 0x55, 0x8f, 0x6c, 0xe7, 0x9f,
+	0xb5, 0xec, 0x56, 0x8c, 0x2f, 0x02, 0x95, 0x18, 0x1b, 0xda, 0x89, 0xf9, 0x36, 0x99, 0xcb, 0xe9,
+	0x72, 0x68, 0x6b, 0x2b, 0xa1, 0xc5, 0xae, 0x72, 0xe7, 0x72, 0x2a, 0x12, 0x93, 0x2e, 0x43, 0x91,
+	0x7d, 0xbd, 0xb5, 0xa8, 0x7c, 0x0d, 0x0e, 0xb7, 0x69, 0xd9, 0x20, 0xfd, 0x26, 0x88, 0x7c, 0xa6,
+	0x10, 0xd8, 0x3d, 0x89, 0x08, 0xc3, 0xb1, 0xeb, 0xdd, 0x5c, 0x4a, 0xa1, 0x92, 0xd7, 0x60, 0x90,
+	0xb1, 0xde, 0x08, 0xf2, 0x18, 0x9a, 0xae, 0xef, 0x73, 0xdf, 0x6c, 0x16, 0x5d, 0xba, 0xbc, 0x3e,
+	0xd3, 0x27, 0x64, 0x0f, 0x36, 0x12, 0x3e, 0x13, 0x0b, 0xee, 0x8f, 0x36, 0xd6, 0x41, 0xd9, 0x99,
+	0xb3, 0x0f, 0x0d, 0x34, 0x4c, 0xda, 0xd0, 0x30, 0x55, 0xd0, 0x86, 0xc6, 0xe9, 0xc9, 0x8b, 0x37,
+	0x43, 0x8b, 0xf4, 0xa0, 0xcd, 0x5e, 0x9d, 0x9f, 0x3f, 0x3b, 0x3a, 0xfe, 0x66, 0x58, 0x73, 0x3e,
+	0x81, 0x41, 0xe6, 0xab, 0x69, 0xf2, 0x6d, 0x68, 0x86, 0xc1, 0x2c, 0x90, 0x2a, 0x3e, 0x7d, 0xa6,
+	0x09, 0xe7, 0xf7, 0xd0, 0xcb, 0x70, 0xaa, 0x4d, 0x3f, 0x83, 0x4e, 0x16, 0xb8, 0xac, 0x3e, 0x36,
+	0x57, 0xee, 0xce, 0x96, 0x08, 0xe7, 0x29, 0x74, 0x73, 0x36, 0xbf, 0x7d, 0x5f, 0x1a, 0x9c, 0x31,
+	0xf4, 0x98, 0x89, 0x89, 0x1a, 0x4b, 0xbf, 0x58, 0xc1, 0x56, 0x18, 0x5a, 0xe6, 0xf0, 0x81, 0x83,
+	0x21, 0x80, 0xee, 0xeb, 0x60, 0x36, 0x0f, 0x5d, 0xa9, 0xe6, 0xfb, 0x47, 0x50, 0x77, 0x7d, 0x7f,
+	0x64, 0xad, 0x47, 0x14, 0xf9, 0xe4, 0x09, 0xb4, 0x74, 0x60, 0x47, 0xb5, 0x75, 0x84, 0x39, 0xc2,
+	0xfa, 0x48, 0x78, 0x1c, 0xba, 0x77, 0xaa, 0x3e, 0xfa, 0xcc, 0x50, 0xce, 0x5f, 0x60, 0x70, 0xa2,
+	0xde, 0x7b, 0x11, 0x99, 0x4a, 0x7e, 0x82, 0x73, 0xf6, 0xd6, 0xdc, 0xa5, 0xe2, 0x79, 0xc5, 0x53,
+	0x54, 0x37, 0xe6, 0x13, 0x91, 0xe8, 0x19, 0xd2, 0x66, 0x86, 0xc2, 0xec, 0xb8, 0x13, 0xc9, 0x75,
+	0x15, 0xb6, 0x99, 0x26, 0x74, 0x3c, 0xd1, 0x1c, 0xd7, 0x85, 0xd8, 0x66, 0x39, 0xed, 0xfc, 0xcb,
+	0x82, 0xde, 0xf2, 0xb2, 0x69, 0x4c, 0x9e, 0xc2, 0x86, 0xa7, 0x3c, 0x59, 0x26, 0xae, 0xec, 0x21,
+	0xcb, 0xce, 0x1f, 0x18, 0x4e, 0xec, 0x1c, 0x8e, 0x13, 0xdf, 0x95, 0xdc, 0x37, 0xd7, 0x5f, 0x32,
+	0xd6, 0x9c, 0xeb, 0x2f, 0x9d, 0x53, 0x3d, 0x97, 0xcc, 0x23, 0x4f, 0x49, 0xea, 0xf5, 0x68, 0xc9,
+	0x38, 0xfc, 0x77, 0x1b, 0xfa, 0xa5, 0xf0, 0x10, 0x0a, 0x4d, 0xb5, 0x6f, 0x90, 0xf5, 0xb8, 0xd9,
+	0x15, 0xfb, 0x06, 0x39, 0x80, 0x9e, 0x59, 0xb0, 0xee, 0x15, 0xeb, 0xd1, 0xe2, 0x0a, 0xf6, 0x02,
+	0x60, 0xb9, 0xd5, 0x90, 0x01, 0x2d, 0xad, 0x46, 0xf6, 0x26, 0x2d, 0xaf, 0x3c, 0x8e, 0xfd, 0xdd,
+	0x7f, 0xfe, 0xf7, 0xf7, 0xda, 0xb6, 0xb3, 0x79, 0xb0, 0xf8, 0xf5, 0x81, 0x87, 0xec, 0x83, 0x31,
+	0x22, 0xbe, 0xb2, 0x3e, 0x25, 0xc7, 0xd0, 0x38, 0xf5, 0xa6, 0x82, 0x0c, 0x68, 0xe9, 0xfd, 0xb6,
+	0x57, 0x68, 0xe7, 0xa7, 0x4a, 0xc7, 0x8f, 0x9c, 0x21, 0xea, 0xe0, 0xef, 0xdc, 0x59, 0x1c, 0xf2,
+	0x03, 0xee, 0x4d, 0x05, 0x2a, 0x79, 0x0c, 0x9d, 0x23, 0xdf, 0x37, 0x2f, 0x7b, 0x36, 0xe1, 0xec,
+	0x2e, 0x2d, 0xbc, 0xdb, 0x1f, 0x63, 0x6b, 0x62, 0x0d, 0xbe, 0x17, 0xf5, 0x14, 0xb6, 0x8e, 0x7c,
+	0xff, 0xac, 0x38, 0x8b, 0xef, 0x83, 0xfe, 0x12, 0xb6, 0xb5, 0xc2, 0x07, 0xa1, 0x3f, 0x85, 0x1e,
+	0x4e, 0x84, 0x7c, 0xba, 0xf7, 0x69, 0x71, 0x67, 0xc8, 0xb1, 0x88, 0x21, 0x87, 0xb0, 0x8d, 0x7f,
+	0xcf, 0x56, 0x5f, 0x84, 0xf7, 0xc9, 0x50, 0xe8, 0x16, 0x1e, 0x71, 0xb2, 0x49, 0xcb, 0x4f, 0xba,
+	0xdd, 0x2b, 0xbe, 0xae, 0xbf, 0xb2, 0xc8, 0x21, 0x6c, 0x9d, 0x71, 0xb9, 0xf2, 0x9a, 0xf5, 0x68,
+	0xe1, 0xb9, 0xb3, 0xb3, 0x01, 0x92, 0x1f, 0xff, 0x16, 0x3e, 0xc8, 0xef, 0x90, 0x8f, 0x95, 0x94,
+	0x6c, 0xd2, 0xf2, 0x6c, 0xb4, 0xfb, 0xb4, 0x34, 0x04, 0x8b, 0xa6, 0xb2, 0x03, 0xd2, 0xa3, 0x85,
+	0x49, 0x67, 0xaf, 0xce, 0x2a, 0xf2, 0x19, 0x0c, 0xb2, 0xf1, 0x66, 0x6e, 0x54, 0x16, 0xe8, 0xd3,
+	0xd2, 0xf4, 0xfb, 0x1c, 0xb6, 0xb3, 0xe6, 0xd5, 0x70, 0x33, 0x44, 0x7a, 0xb4, 0x30, 0xc0, 0xec,
+	0x3e, 0x2d, 0x75, 0xf8, 0x27, 0xb0, 0x79, 0xc6, 0x25, 0xee, 0x94, 0xe9, 0xd7, 0x22, 0xf9, 0x13,
+	0xae, 0xab, 0x6d, 0x6a, 0x96, 0x59, 0xbb, 0x43, 0xf3, 0x8d, 0x54, 0xe3, 0xf0, 0x1c, 0x71, 0xc8,
+	0xae, 0xc6, 0x51, 0xf8, 0xc9, 0x19, 0x97, 0x2f, 0x66, 0x31, 0xa6, 0xeb, 0x01, 0x7a, 0xbf, 0x80,
+	0x8f, 0x0a, 0xf8, 0x0b, 0x9e, 0xcc, 0x82, 0x54, 0x45, 0x74, 0x5d, 0xaa, 0x94, 0xea, 0x7d, 0x18,
+	0x98, 0x5d, 0x78, 0x1d, 0xd8, 0xa3, 0xc5, 0x35, 0xf9, 0x31, 0xb4, 0xf4, 0x5a, 0x4f, 0x80, 0xe6,
+	0xff, 0x22, 0xd8, 0x5d, 0xba, 0xdc, 0xf5, 0xc7, 0x2d, 0xf5, 0x9b, 0xc6, 0xe7, 0xdf, 0x0f, 0x00,
+	0x41, 0xd5, 0x3d, 0x16, 0x0f, 0x11, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AccessControlClient is the client API for AccessControl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AccessControlClient interface {
+	Check(ctx context.Context, in *AccessControlReq, opts ...grpc.CallOption) (*AccessControlResp, error)
+	ExplainCheck(ctx context.Context, in *AccessControlReq, opts ...grpc.CallOption) (*ExplainResp, error)
+	BatchCheck(ctx context.Context, in *BatchCheckReq, opts ...grpc.CallOption) (*BatchCheckResp, error)
+	Echo(ctx context.Context, in *StringMessage, opts ...grpc.CallOption) (*StringMessage, error)
+	AddPolicy(ctx context.Context, in *Policy, opts ...grpc.CallOption) (*PolicyResp, error)
+	RemovePolicy(ctx context.Context, in *Policy, opts ...grpc.CallOption) (*PolicyResp, error)
+	AddGroupingPolicy(ctx context.Context, in *Policy, opts ...grpc.CallOption) (*PolicyResp, error)
+	RemoveGroupingPolicy(ctx context.Context, in *Policy, opts ...grpc.CallOption) (*PolicyResp, error)
+	ListPolicies(ctx context.Context, in *PolicyFilter, opts ...grpc.CallOption) (*PolicyList, error)
+	ListGroupingPolicies(ctx context.Context, in *PolicyFilter, opts ...grpc.CallOption) (*PolicyList, error)
+	WatchPolicy(ctx context.Context, in *WatchPolicyReq, opts ...grpc.CallOption) (AccessControl_WatchPolicyClient, error)
+	GetPolicySnapshot(ctx context.Context, in *SnapshotReq, opts ...grpc.CallOption) (*PolicySnapshot, error)
+	ListPolicyRevisions(ctx context.Context, in *RevisionFilter, opts ...grpc.CallOption) (*RevisionList, error)
+	GetPolicyRevision(ctx context.Context, in *RevisionReq, opts ...grpc.CallOption) (*PolicyRevision, error)
+	RollbackPolicy(ctx context.Context, in *RevisionReq, opts ...grpc.CallOption) (*RollbackResp, error)
+	SimulatePolicyChange(ctx context.Context, in *SimulateReq, opts ...grpc.CallOption) (*SimulateResp, error)
+	GetRolesForUser(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*RoleList, error)
+	GetUsersForRole(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*RoleList, error)
+	GetImplicitRolesForUser(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*RoleList, error)
+	GetImplicitPermissionsForUser(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*PolicyList, error)
+	HasRoleForUser(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*HasRoleResp, error)
+	WhoCan(ctx context.Context, in *WhoCanReq, opts ...grpc.CallOption) (*WhoCanResp, error)
+}
+
+type accessControlClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAccessControlClient(cc *grpc.ClientConn) AccessControlClient {
+	return &accessControlClient{cc}
+}
+
+func (c *accessControlClient) Check(ctx context.Context, in *AccessControlReq, opts ...grpc.CallOption) (*AccessControlResp, error) {
+	out := new(AccessControlResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/Check", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) ExplainCheck(ctx context.Context, in *AccessControlReq, opts ...grpc.CallOption) (*ExplainResp, error) {
+	out := new(ExplainResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/ExplainCheck", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) BatchCheck(ctx context.Context, in *BatchCheckReq, opts ...grpc.CallOption) (*BatchCheckResp, error) {
+	out := new(BatchCheckResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/BatchCheck", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) Echo(ctx context.Context, in *StringMessage, opts ...grpc.CallOption) (*StringMessage, error) {
+	out := new(StringMessage)
+	err := c.cc.Invoke(ctx, "/AccessControl/Echo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) AddPolicy(ctx context.Context, in *Policy, opts ...grpc.CallOption) (*PolicyResp, error) {
+	out := new(PolicyResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/AddPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) RemovePolicy(ctx context.Context, in *Policy, opts ...grpc.CallOption) (*PolicyResp, error) {
+	out := new(PolicyResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/RemovePolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) AddGroupingPolicy(ctx context.Context, in *Policy, opts ...grpc.CallOption) (*PolicyResp, error) {
+	out := new(PolicyResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/AddGroupingPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) RemoveGroupingPolicy(ctx context.Context, in *Policy, opts ...grpc.CallOption) (*PolicyResp, error) {
+	out := new(PolicyResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/RemoveGroupingPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) ListPolicies(ctx context.Context, in *PolicyFilter, opts ...grpc.CallOption) (*PolicyList, error) {
+	out := new(PolicyList)
+	err := c.cc.Invoke(ctx, "/AccessControl/ListPolicies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) ListGroupingPolicies(ctx context.Context, in *PolicyFilter, opts ...grpc.CallOption) (*PolicyList, error) {
+	out := new(PolicyList)
+	err := c.cc.Invoke(ctx, "/AccessControl/ListGroupingPolicies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) WatchPolicy(ctx context.Context, in *WatchPolicyReq, opts ...grpc.CallOption) (AccessControl_WatchPolicyClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AccessControl_serviceDesc.Streams[0], "/AccessControl/WatchPolicy", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &accessControlWatchPolicyClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AccessControl_WatchPolicyClient interface {
+	Recv() (*PolicyEvent, error)
+	grpc.ClientStream
+}
+
+type accessControlWatchPolicyClient struct {
+	grpc.ClientStream
+}
+
+func (x *accessControlWatchPolicyClient) Recv() (*PolicyEvent, error) {
+	m := new(PolicyEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *accessControlClient) GetPolicySnapshot(ctx context.Context, in *SnapshotReq, opts ...grpc.CallOption) (*PolicySnapshot, error) {
+	out := new(PolicySnapshot)
+	err := c.cc.Invoke(ctx, "/AccessControl/GetPolicySnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) ListPolicyRevisions(ctx context.Context, in *RevisionFilter, opts ...grpc.CallOption) (*RevisionList, error) {
+	out := new(RevisionList)
+	err := c.cc.Invoke(ctx, "/AccessControl/ListPolicyRevisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) GetPolicyRevision(ctx context.Context, in *RevisionReq, opts ...grpc.CallOption) (*PolicyRevision, error) {
+	out := new(PolicyRevision)
+	err := c.cc.Invoke(ctx, "/AccessControl/GetPolicyRevision", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) RollbackPolicy(ctx context.Context, in *RevisionReq, opts ...grpc.CallOption) (*RollbackResp, error) {
+	out := new(RollbackResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/RollbackPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) SimulatePolicyChange(ctx context.Context, in *SimulateReq, opts ...grpc.CallOption) (*SimulateResp, error) {
+	out := new(SimulateResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/SimulatePolicyChange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) GetRolesForUser(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*RoleList, error) {
+	out := new(RoleList)
+	err := c.cc.Invoke(ctx, "/AccessControl/GetRolesForUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) GetUsersForRole(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*RoleList, error) {
+	out := new(RoleList)
+	err := c.cc.Invoke(ctx, "/AccessControl/GetUsersForRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) GetImplicitRolesForUser(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*RoleList, error) {
+	out := new(RoleList)
+	err := c.cc.Invoke(ctx, "/AccessControl/GetImplicitRolesForUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) GetImplicitPermissionsForUser(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*PolicyList, error) {
+	out := new(PolicyList)
+	err := c.cc.Invoke(ctx, "/AccessControl/GetImplicitPermissionsForUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) HasRoleForUser(ctx context.Context, in *RoleReq, opts ...grpc.CallOption) (*HasRoleResp, error) {
+	out := new(HasRoleResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/HasRoleForUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlClient) WhoCan(ctx context.Context, in *WhoCanReq, opts ...grpc.CallOption) (*WhoCanResp, error) {
+	out := new(WhoCanResp)
+	err := c.cc.Invoke(ctx, "/AccessControl/WhoCan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccessControlServer is the server API for AccessControl service.
+type AccessControlServer interface {
+	Check(context.Context, *AccessControlReq) (*AccessControlResp, error)
+	ExplainCheck(context.Context, *AccessControlReq) (*ExplainResp, error)
+	BatchCheck(context.Context, *BatchCheckReq) (*BatchCheckResp, error)
+	Echo(context.Context, *StringMessage) (*StringMessage, error)
+	AddPolicy(context.Context, *Policy) (*PolicyResp, error)
+	RemovePolicy(context.Context, *Policy) (*PolicyResp, error)
+	AddGroupingPolicy(context.Context, *Policy) (*PolicyResp, error)
+	RemoveGroupingPolicy(context.Context, *Policy) (*PolicyResp, error)
+	ListPolicies(context.Context, *PolicyFilter) (*PolicyList, error)
+	ListGroupingPolicies(context.Context, *PolicyFilter) (*PolicyList, error)
+	WatchPolicy(*WatchPolicyReq, AccessControl_WatchPolicyServer) error
+	GetPolicySnapshot(context.Context, *SnapshotReq) (*PolicySnapshot, error)
+	ListPolicyRevisions(context.Context, *RevisionFilter) (*RevisionList, error)
+	GetPolicyRevision(context.Context, *RevisionReq) (*PolicyRevision, error)
+	RollbackPolicy(context.Context, *RevisionReq) (*RollbackResp, error)
+	SimulatePolicyChange(context.Context, *SimulateReq) (*SimulateResp, error)
+	GetRolesForUser(context.Context, *RoleReq) (*RoleList, error)
+	GetUsersForRole(context.Context, *RoleReq) (*RoleList, error)
+	GetImplicitRolesForUser(context.Context, *RoleReq) (*RoleList, error)
+	GetImplicitPermissionsForUser(context.Context, *RoleReq) (*PolicyList, error)
+	HasRoleForUser(context.Context, *RoleReq) (*HasRoleResp, error)
+	WhoCan(context.Context, *WhoCanReq) (*WhoCanResp, error)
+}
+
+// UnimplementedAccessControlServer can be embedded to have forward compatible implementations.
+type UnimplementedAccessControlServer struct {
+}
+
+func (*UnimplementedAccessControlServer) Check(ctx context.Context, req *AccessControlReq) (*AccessControlResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+}
+func (*UnimplementedAccessControlServer) ExplainCheck(ctx context.Context, req *AccessControlReq) (*ExplainResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainCheck not implemented")
+}
+func (*UnimplementedAccessControlServer) BatchCheck(ctx context.Context, req *BatchCheckReq) (*BatchCheckResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCheck not implemented")
+}
+func (*UnimplementedAccessControlServer) Echo(ctx context.Context, req *StringMessage) (*StringMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Echo not implemented")
+}
+func (*UnimplementedAccessControlServer) AddPolicy(ctx context.Context, req *Policy) (*PolicyResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPolicy not implemented")
+}
+func (*UnimplementedAccessControlServer) RemovePolicy(ctx context.Context, req *Policy) (*PolicyResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemovePolicy not implemented")
+}
+func (*UnimplementedAccessControlServer) AddGroupingPolicy(ctx context.Context, req *Policy) (*PolicyResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddGroupingPolicy not implemented")
+}
+func (*UnimplementedAccessControlServer) RemoveGroupingPolicy(ctx context.Context, req *Policy) (*PolicyResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveGroupingPolicy not implemented")
+}
+func (*UnimplementedAccessControlServer) ListPolicies(ctx context.Context, req *PolicyFilter) (*PolicyList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPolicies not implemented")
+}
+func (*UnimplementedAccessControlServer) ListGroupingPolicies(ctx context.Context, req *PolicyFilter) (*PolicyList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroupingPolicies not implemented")
+}
+func (*UnimplementedAccessControlServer) WatchPolicy(req *WatchPolicyReq, srv AccessControl_WatchPolicyServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPolicy not implemented")
+}
+func (*UnimplementedAccessControlServer) GetPolicySnapshot(ctx context.Context, req *SnapshotReq) (*PolicySnapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPolicySnapshot not implemented")
+}
+func (*UnimplementedAccessControlServer) ListPolicyRevisions(ctx context.Context, req *RevisionFilter) (*RevisionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPolicyRevisions not implemented")
+}
+func (*UnimplementedAccessControlServer) GetPolicyRevision(ctx context.Context, req *RevisionReq) (*PolicyRevision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPolicyRevision not implemented")
+}
+func (*UnimplementedAccessControlServer) RollbackPolicy(ctx context.Context, req *RevisionReq) (*RollbackResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackPolicy not implemented")
+}
+func (*UnimplementedAccessControlServer) SimulatePolicyChange(ctx context.Context, req *SimulateReq) (*SimulateResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimulatePolicyChange not implemented")
+}
+func (*UnimplementedAccessControlServer) GetRolesForUser(ctx context.Context, req *RoleReq) (*RoleList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRolesForUser not implemented")
+}
+func (*UnimplementedAccessControlServer) GetUsersForRole(ctx context.Context, req *RoleReq) (*RoleList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsersForRole not implemented")
+}
+func (*UnimplementedAccessControlServer) GetImplicitRolesForUser(ctx context.Context, req *RoleReq) (*RoleList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetImplicitRolesForUser not implemented")
+}
+func (*UnimplementedAccessControlServer) GetImplicitPermissionsForUser(ctx context.Context, req *RoleReq) (*PolicyList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetImplicitPermissionsForUser not implemented")
+}
+func (*UnimplementedAccessControlServer) HasRoleForUser(ctx context.Context, req *RoleReq) (*HasRoleResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HasRoleForUser not implemented")
+}
+func (*UnimplementedAccessControlServer) WhoCan(ctx context.Context, req *WhoCanReq) (*WhoCanResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WhoCan not implemented")
+}
+
+func RegisterAccessControlServer(s *grpc.Server, srv AccessControlServer) {
+	s.RegisterService(&_AccessControl_serviceDesc, srv)
+}
+
+func _AccessControl_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccessControlReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/Check",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).Check(ctx, req.(*AccessControlReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_ExplainCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccessControlReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).ExplainCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/ExplainCheck",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).ExplainCheck(ctx, req.(*AccessControlReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_BatchCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCheckReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).BatchCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/BatchCheck",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).BatchCheck(ctx, req.(*BatchCheckReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_Echo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StringMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).Echo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/Echo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).Echo(ctx, req.(*StringMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_AddPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Policy)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).AddPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/AddPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).AddPolicy(ctx, req.(*Policy))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_RemovePolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Policy)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).RemovePolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/AccessControl/Echo",
+		FullMethod: "/AccessControl/RemovePolicy",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AccessControlServer).Echo(ctx, req.(*StringMessage))
+		return srv.(AccessControlServer).RemovePolicy(ctx, req.(*Policy))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_AddGroupingPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Policy)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).AddGroupingPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/AddGroupingPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).AddGroupingPolicy(ctx, req.(*Policy))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_RemoveGroupingPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Policy)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).RemoveGroupingPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/RemoveGroupingPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).RemoveGroupingPolicy(ctx, req.(*Policy))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_ListPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PolicyFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).ListPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/ListPolicies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).ListPolicies(ctx, req.(*PolicyFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_ListGroupingPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PolicyFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).ListGroupingPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/ListGroupingPolicies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).ListGroupingPolicies(ctx, req.(*PolicyFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_WatchPolicy_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPolicyReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AccessControlServer).WatchPolicy(m, &accessControlWatchPolicyServer{stream})
+}
+
+type AccessControl_WatchPolicyServer interface {
+	Send(*PolicyEvent) error
+	grpc.ServerStream
+}
+
+type accessControlWatchPolicyServer struct {
+	grpc.ServerStream
+}
+
+func (x *accessControlWatchPolicyServer) Send(m *PolicyEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AccessControl_GetPolicySnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).GetPolicySnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/GetPolicySnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).GetPolicySnapshot(ctx, req.(*SnapshotReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_ListPolicyRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).ListPolicyRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/ListPolicyRevisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).ListPolicyRevisions(ctx, req.(*RevisionFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_GetPolicyRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).GetPolicyRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/GetPolicyRevision",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).GetPolicyRevision(ctx, req.(*RevisionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_RollbackPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).RollbackPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/RollbackPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).RollbackPolicy(ctx, req.(*RevisionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_SimulatePolicyChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulateReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).SimulatePolicyChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/SimulatePolicyChange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).SimulatePolicyChange(ctx, req.(*SimulateReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_GetRolesForUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoleReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).GetRolesForUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/GetRolesForUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).GetRolesForUser(ctx, req.(*RoleReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_GetUsersForRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoleReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).GetUsersForRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/GetUsersForRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).GetUsersForRole(ctx, req.(*RoleReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_GetImplicitRolesForUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoleReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).GetImplicitRolesForUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/GetImplicitRolesForUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).GetImplicitRolesForUser(ctx, req.(*RoleReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_GetImplicitPermissionsForUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoleReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).GetImplicitPermissionsForUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/GetImplicitPermissionsForUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).GetImplicitPermissionsForUser(ctx, req.(*RoleReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_HasRoleForUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoleReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).HasRoleForUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/HasRoleForUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).HasRoleForUser(ctx, req.(*RoleReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControl_WhoCan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WhoCanReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServer).WhoCan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AccessControl/WhoCan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServer).WhoCan(ctx, req.(*WhoCanReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -293,11 +2599,93 @@ var _AccessControl_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Check",
 			Handler:    _AccessControl_Check_Handler,
 		},
+		{
+			MethodName: "ExplainCheck",
+			Handler:    _AccessControl_ExplainCheck_Handler,
+		},
+		{
+			MethodName: "BatchCheck",
+			Handler:    _AccessControl_BatchCheck_Handler,
+		},
 		{
 			MethodName: "Echo",
 			Handler:    _AccessControl_Echo_Handler,
 		},
+		{
+			MethodName: "AddPolicy",
+			Handler:    _AccessControl_AddPolicy_Handler,
+		},
+		{
+			MethodName: "RemovePolicy",
+			Handler:    _AccessControl_RemovePolicy_Handler,
+		},
+		{
+			MethodName: "AddGroupingPolicy",
+			Handler:    _AccessControl_AddGroupingPolicy_Handler,
+		},
+		{
+			MethodName: "RemoveGroupingPolicy",
+			Handler:    _AccessControl_RemoveGroupingPolicy_Handler,
+		},
+		{
+			MethodName: "ListPolicies",
+			Handler:    _AccessControl_ListPolicies_Handler,
+		},
+		{
+			MethodName: "ListGroupingPolicies",
+			Handler:    _AccessControl_ListGroupingPolicies_Handler,
+		},
+		{
+			MethodName: "GetPolicySnapshot",
+			Handler:    _AccessControl_GetPolicySnapshot_Handler,
+		},
+		{
+			MethodName: "ListPolicyRevisions",
+			Handler:    _AccessControl_ListPolicyRevisions_Handler,
+		},
+		{
+			MethodName: "GetPolicyRevision",
+			Handler:    _AccessControl_GetPolicyRevision_Handler,
+		},
+		{
+			MethodName: "RollbackPolicy",
+			Handler:    _AccessControl_RollbackPolicy_Handler,
+		},
+		{
+			MethodName: "SimulatePolicyChange",
+			Handler:    _AccessControl_SimulatePolicyChange_Handler,
+		},
+		{
+			MethodName: "GetRolesForUser",
+			Handler:    _AccessControl_GetRolesForUser_Handler,
+		},
+		{
+			MethodName: "GetUsersForRole",
+			Handler:    _AccessControl_GetUsersForRole_Handler,
+		},
+		{
+			MethodName: "GetImplicitRolesForUser",
+			Handler:    _AccessControl_GetImplicitRolesForUser_Handler,
+		},
+		{
+			MethodName: "GetImplicitPermissionsForUser",
+			Handler:    _AccessControl_GetImplicitPermissionsForUser_Handler,
+		},
+		{
+			MethodName: "HasRoleForUser",
+			Handler:    _AccessControl_HasRoleForUser_Handler,
+		},
+		{
+			MethodName: "WhoCan",
+			Handler:    _AccessControl_WhoCan_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPolicy",
+			Handler:       _AccessControl_WatchPolicy_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/access_control.proto",
 }
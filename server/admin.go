@@ -0,0 +1,121 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+)
+
+func (s *server) AddPolicy(ctx context.Context, req *proto.Policy) (*proto.PolicyResp, error) {
+	return s.updatePolicy(ctx, "p", req, true)
+}
+
+func (s *server) RemovePolicy(ctx context.Context, req *proto.Policy) (*proto.PolicyResp, error) {
+	return s.updatePolicy(ctx, "p", req, false)
+}
+
+func (s *server) AddGroupingPolicy(ctx context.Context, req *proto.Policy) (*proto.PolicyResp, error) {
+	return s.updatePolicy(ctx, "g", req, true)
+}
+
+func (s *server) RemoveGroupingPolicy(ctx context.Context, req *proto.Policy) (*proto.PolicyResp, error) {
+	return s.updatePolicy(ctx, "g", req, false)
+}
+
+func (s *server) ListPolicies(ctx context.Context, req *proto.PolicyFilter) (*proto.PolicyList, error) {
+	return s.listPolicies("p", req)
+}
+
+func (s *server) ListGroupingPolicies(ctx context.Context, req *proto.PolicyFilter) (*proto.PolicyList, error) {
+	return s.listPolicies("g", req)
+}
+
+func (s *server) updatePolicy(ctx context.Context, sec string, req *proto.Policy, add bool) (*proto.PolicyResp, error) {
+	if s.leader != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "this server replicates the policy of %s; send policy changes there", s.leader)
+	}
+	var (
+		res bool
+		err error
+	)
+	if add {
+		res, err = s.enforcer.addPolicy(sec, req.GetDom(), req.GetParams(), callerFromContext(ctx))
+	} else {
+		res, err = s.enforcer.removePolicy(sec, req.GetDom(), req.GetParams(), callerFromContext(ctx))
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+	log.Println("policy updated:", sec, req.GetDom(), req.GetParams(), "add:", add, "changed:", res)
+	return &proto.PolicyResp{Res: res}, nil
+}
+
+func (s *server) listPolicies(sec string, req *proto.PolicyFilter) (*proto.PolicyList, error) {
+	if req.GetFieldIndex() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "field_index must not be negative")
+	}
+	rules, version, err := s.enforcer.policies(sec, req.GetDom(), int(req.GetFieldIndex()), req.GetFieldValues()...)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &proto.PolicyList{Policies: toPolicies(rules), PolicyVersion: version}, nil
+}
+
+func toPolicies(rules [][]string) []*proto.Policy {
+	policies := make([]*proto.Policy, 0, len(rules))
+	for _, rule := range rules {
+		policies = append(policies, &proto.Policy{Params: append([]string(nil), rule...)})
+	}
+	return policies
+}
+
+// WatchPolicy streams policy changes after req.from_policy_version, or from
+// now if it is zero. A watcher that falls behind is disconnected with
+// ResourceExhausted and should resume from the last version it received.
+func (s *server) WatchPolicy(req *proto.WatchPolicyReq, stream proto.AccessControl_WatchPolicyServer) error {
+	backlog, events, cancel, err := s.enforcer.feed.subscribe(req.GetFromPolicyVersion())
+	if err != nil {
+		return status.Error(codes.OutOfRange, err.Error())
+	}
+	defer cancel()
+	log.Println("policy watcher connected from version", req.GetFromPolicyVersion())
+
+	for _, ev := range backlog {
+		if err := stream.Send(toPolicyEvent(ev)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "the server is shutting down")
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher fell behind; resume from the last received policy version")
+			}
+			if err := stream.Send(toPolicyEvent(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toPolicyEvent(ev policyEvent) *proto.PolicyEvent {
+	pev := &proto.PolicyEvent{PolicyVersion: ev.version, Sec: ev.sec, Timestamp: ev.time.Unix()}
+	switch ev.typ {
+	case eventAdd:
+		pev.Type = proto.PolicyEvent_ADD
+	case eventRemove:
+		pev.Type = proto.PolicyEvent_REMOVE
+	default:
+		pev.Type = proto.PolicyEvent_RELOAD
+	}
+	if ev.rule != nil {
+		pev.Policy = &proto.Policy{Params: append([]string(nil), ev.rule...)}
+	}
+	return pev
+}
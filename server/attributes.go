@@ -0,0 +1,108 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"errors"
+	"time"
+)
+
+// attributes is a bag of typed request attributes. It is passed to the
+// matcher for the sub_attrs, obj_attrs and env_attrs request fields and read
+// with the attr and hasAttr functions, e.g.
+//
+//	m = attr(r.sub_attrs, "department") == attr(r.obj_attrs, "department")
+//
+// Values are strings, float64 numbers or bools.
+type attributes map[string]interface{}
+
+func toAttributes(m map[string]*proto.AttributeValue) attributes {
+	attrs := make(attributes, len(m))
+	for name, v := range m {
+		switch k := v.GetKind().(type) {
+		case *proto.AttributeValue_StringValue:
+			attrs[name] = k.StringValue
+		case *proto.AttributeValue_NumberValue:
+			attrs[name] = k.NumberValue
+		case *proto.AttributeValue_BoolValue:
+			attrs[name] = k.BoolValue
+		}
+	}
+	return attrs
+}
+
+// fromAttributes converts attributes back into their wire form.
+func fromAttributes(attrs attributes) map[string]*proto.AttributeValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]*proto.AttributeValue, len(attrs))
+	for name, v := range attrs {
+		switch v := v.(type) {
+		case string:
+			m[name] = &proto.AttributeValue{Kind: &proto.AttributeValue_StringValue{StringValue: v}}
+		case float64:
+			m[name] = &proto.AttributeValue{Kind: &proto.AttributeValue_NumberValue{NumberValue: v}}
+		case bool:
+			m[name] = &proto.AttributeValue{Kind: &proto.AttributeValue_BoolValue{BoolValue: v}}
+		}
+	}
+	return m
+}
+
+// envAttributes adds the server's view of the environment to the attributes
+// sent by the caller: "time" is the server clock in Unix seconds. A time the
+// caller supplied is kept only with trustTime, since it would let callers
+// step into or back into the windows of time-bounded rules.
+func envAttributes(m map[string]*proto.AttributeValue, trustTime bool) attributes {
+	env := toAttributes(m)
+	if _, ok := env["time"]; !ok || !trustTime {
+		env["time"] = float64(time.Now().Unix())
+	}
+	return env
+}
+
+// missingAttr is what attr yields for a missing attribute. The matcher
+// compares with reflect.DeepEqual, under which a non-nil func equals nothing,
+// not even another missing attribute, so r.sub == attr(r.obj_attrs, "owner")
+// cannot hold for an empty subject and an object without owner.
+type missingAttr func()
+
+var missing = missingAttr(func() {})
+
+// attrFunc implements attr(attrs, name). A missing attribute is unequal to
+// every value instead of aborting the evaluation.
+func attrFunc(args ...interface{}) (interface{}, error) {
+	attrs, name, err := attrArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := attrs[name]; ok {
+		return v, nil
+	}
+	return missing, nil
+}
+
+// hasAttrFunc implements hasAttr(attrs, name).
+func hasAttrFunc(args ...interface{}) (interface{}, error) {
+	attrs, name, err := attrArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := attrs[name]
+	return ok, nil
+}
+
+func attrArgs(args []interface{}) (attributes, string, error) {
+	if len(args) != 2 {
+		return nil, "", errors.New("attribute functions take an attribute field and a name")
+	}
+	attrs, ok := args[0].(attributes)
+	if !ok {
+		return nil, "", errors.New("first argument must be r.sub_attrs, r.obj_attrs or r.env_attrs")
+	}
+	name, ok := args[1].(string)
+	if !ok {
+		return nil, "", errors.New("attribute name must be a string")
+	}
+	return attrs, name, nil
+}
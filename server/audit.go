@@ -0,0 +1,43 @@
+package main
+
+import (
+	"casbinsvr/auditlog"
+	"context"
+	"google.golang.org/grpc/peer"
+	"time"
+)
+
+// auditDecision appends a decision to the audit log. Callers fail the request
+// if it cannot be recorded.
+func (s *server) auditDecision(ctx context.Context, method string, r request, d decision) error {
+	return s.audit.Append(&auditlog.Entry{
+		Time:   time.Now().UTC(),
+		Caller: callerFromContext(ctx),
+		Method: method,
+		Request: auditlog.Request{
+			Sub:      r.sub,
+			Dom:      r.dom,
+			Obj:      r.obj,
+			Act:      r.act,
+			SubAttrs: r.subAttrs,
+			ObjAttrs: r.objAttrs,
+			EnvAttrs: r.envAttrs,
+		},
+		Decision:      d.res,
+		Rule:          d.rule,
+		PolicyVersion: d.version,
+	})
+}
+
+// callerFromContext identifies the caller of an RPC by its authenticated
+// identity, if any, and its network address.
+func callerFromContext(ctx context.Context) string {
+	addr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+	if id := identityFromContext(ctx); id != "" {
+		return id + " (" + addr + ")"
+	}
+	return addr
+}
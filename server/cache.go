@@ -0,0 +1,102 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// decision is the outcome of a Check together with the policy version it was
+// evaluated against and, when known, the rule that decided it.
+type decision struct {
+	res  bool
+	rule []string
+	// generation and version of the policy, see policyEnforcer
+	generation, version uint64
+}
+
+// decisionCache is a size- and TTL-bounded LRU cache of decisions keyed on
+// the request. Entries remember the policy version they were computed for
+// and are ignored as soon as the policy changes, so an edit or reload
+// invalidates exactly the decisions made before it.
+type decisionCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generation and version of the newest policy seen by put
+	generation, version uint64
+}
+
+type cacheEntry struct {
+	key     string
+	d       decision
+	expires time.Time
+}
+
+// newDecisionCache returns a cache holding up to size decisions for at most
+// ttl, or nil if size is not positive. A nil cache never hits.
+func newDecisionCache(size int, ttl time.Duration) *decisionCache {
+	if size <= 0 {
+		return nil
+	}
+	return &decisionCache{size: size, ttl: ttl, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// cacheKey is the full request tuple. Requests carrying attributes are not
+// cached; their decisions depend on values such as the request time.
+func cacheKey(r request) string {
+	return r.sub + "\x00" + r.dom + "\x00" + r.obj + "\x00" + r.act
+}
+
+// get returns the decision cached for key if it was made under the policy
+// of the given generation and version.
+func (c *decisionCache) get(key string, generation, version uint64) (decision, bool) {
+	if c == nil {
+		return decision{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return decision{}, false
+	}
+	e := el.Value.(*cacheEntry)
+	if e.d.generation != generation || e.d.version != version || time.Now().After(e.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return decision{}, false
+	}
+	c.lru.MoveToFront(el)
+	return e.d, true
+}
+
+// put caches d for key. The first decision of a newer policy drops
+// everything cached for older ones; a newer generation is newer whatever
+// its version.
+func (c *decisionCache) put(key string, d decision) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d.generation < c.generation || d.generation == c.generation && d.version < c.version {
+		return
+	}
+	if d.generation > c.generation || d.version > c.version {
+		c.entries = make(map[string]*list.Element)
+		c.lru.Init()
+		c.generation, c.version = d.generation, d.version
+	}
+	if el, ok := c.entries[key]; ok {
+		c.lru.Remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, d: d, expires: time.Now().Add(c.ttl)})
+	for c.lru.Len() > c.size {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.entries, el.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"github.com/casbin/casbin"
+	"sort"
+	"strings"
+)
+
+// effects maps the strategies selectable with -effect onto the policy_effect
+// expressions understood by casbin's default effector.
+var effects = map[string]string{
+	// any matching allow rule grants access
+	"allow-override": "some(where (p_eft == allow))",
+	// access is granted unless a matching rule denies it
+	"deny-override": "!some(where (p_eft == deny))",
+	// a matching allow rule is needed and no matching rule may deny
+	"allow-and-deny": "some(where (p_eft == allow)) && !some(where (p_eft == deny))",
+	// the first matching rule in policy order decides
+	"priority": "priority(p_eft) || deny",
+}
+
+// effectNames lists the strategies for flag help and error messages.
+func effectNames() string {
+	names := make([]string, 0, len(effects))
+	for name := range effects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func checkEffect(name string) error {
+	if _, ok := effects[name]; name != "" && !ok {
+		return fmt.Errorf("unknown policy effect %q, want one of %s", name, effectNames())
+	}
+	return nil
+}
+
+// setEffect replaces the model's policy_effect with the named strategy. An
+// empty name keeps the effect declared in the model file. Strategies that
+// read the eft column need a model with one; casbin would otherwise take
+// every matching rule for an allow.
+func setEffect(e *casbin.Enforcer, name string) error {
+	if err := checkEffect(name); err != nil {
+		return err
+	}
+	if name != "" && name != "allow-override" && !hasToken(e.GetModel(), "p", "p_eft") {
+		return fmt.Errorf("policy effect %s needs an eft column in the model's policy definition", name)
+	}
+	if name != "" {
+		e.GetModel()["e"]["e"].Value = effects[name]
+	}
+	return nil
+}
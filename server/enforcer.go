@@ -0,0 +1,525 @@
+package main
+
+import (
+	"fmt"
+	"github.com/casbin/casbin"
+	"github.com/casbin/casbin/model"
+	"github.com/casbin/casbin/persist"
+	defaultrolemanager "github.com/casbin/casbin/rbac/default-role-manager"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// policyEnforcer owns the long-lived Casbin enforcer shared by all requests.
+// The model and policy files are polled for changes and a freshly validated
+// enforcer is swapped in; the old one is kept if the new files fail to load.
+// Policy rules are read from and written to adapter, which stores them in the
+// file at policyPath.
+type policyEnforcer struct {
+	modelPath  string
+	policyPath string
+	adapter    persist.Adapter
+	effect     string
+
+	mu sync.RWMutex
+	e  *casbin.Enforcer
+	// version counts reloads and edits of the live policy; it is changed
+	// under mu but may be read atomically without it
+	version uint64
+	// generation counts installs that moved the version backwards, e.g. to a
+	// restarted leader's, after which versions no longer identify a policy;
+	// changed under mu
+	generation uint64
+	// attrs is 1 if the model's request definition uses attribute fields
+	attrs int32
+
+	// feed publishes every change, in version order
+	feed *policyFeed
+	// history records every change as a revision when not nil, see
+	// history.go; it is set before the policy is served
+	history *policyHistory
+
+	// writeMu serializes reloads and policy edits so an edit is never
+	// overwritten by a reload that read the file before it was saved.
+	writeMu sync.Mutex
+
+	// modification times of the files as last seen, guarded by writeMu
+	modelMod  time.Time
+	policyMod time.Time
+}
+
+func newPolicyEnforcer(modelPath, policyPath string, adapter persist.Adapter, effect string) (*policyEnforcer, error) {
+	pe := &policyEnforcer{
+		modelPath:  modelPath,
+		policyPath: policyPath,
+		adapter:    adapter,
+		effect:     effect,
+		feed:       newPolicyFeed(FEED_SIZE),
+	}
+	pe.changed()
+	if err := pe.reload(); err != nil {
+		return nil, err
+	}
+	return pe, nil
+}
+
+// customFunctions are registered on every enforcer in addition to casbin's
+// built-in matcher functions.
+var customFunctions = map[string]func(args ...interface{}) (interface{}, error){
+	"attr":    attrFunc,
+	"hasAttr": hasAttrFunc,
+	"active":  activeFunc,
+}
+
+// loadEnforcer builds an enforcer from the model file and the adapter's rules
+// and reports any parse errors instead of panicking or silently falling back
+// to an empty policy. A non-empty effect overrides the model's policy_effect,
+// see effects. Auto-save is off; edits are persisted by persistRule.
+func loadEnforcer(modelPath string, adapter persist.Adapter, effect string) (*casbin.Enforcer, error) {
+	e, err := casbin.NewEnforcerSafe(modelPath, adapter, false)
+	if err != nil {
+		return nil, fmt.Errorf("load model %s: %v", modelPath, err)
+	}
+	e.EnableAutoSave(false)
+	if err := setEffect(e, effect); err != nil {
+		return nil, err
+	}
+	for name, function := range customFunctions {
+		e.AddFunction(name, function)
+	}
+	// gActive reads the role rules of this enforcer's model
+	e.AddFunction("gActive", groupActiveFunc(e.GetModel()))
+	if _, timed := whenIndex(e.GetModel(), "g"); timed {
+		e.SetRoleManager(timedRoleManager{defaultrolemanager.NewRoleManager(10)})
+	}
+	if err := e.LoadPolicy(); err != nil {
+		return nil, fmt.Errorf("load policy: %v", err)
+	}
+	return e, nil
+}
+
+func (pe *policyEnforcer) reload() error {
+	e, err := loadEnforcer(pe.modelPath, pe.adapter, pe.effect)
+	countReload(pe.policyPath, err)
+	if err != nil {
+		return err
+	}
+	if err := pe.recordLoad(e); err != nil {
+		return fmt.Errorf("record revision: %v", err)
+	}
+	pe.install(e, pe.Version()+1)
+	return nil
+}
+
+// install swaps in e as the live enforcer at the given policy version and
+// publishes a reload event. Callers hold writeMu.
+func (pe *policyEnforcer) install(e *casbin.Enforcer, version uint64) {
+	var attrs int32
+	if attributeModel(e.GetModel()) || timedModel(e.GetModel()) {
+		attrs = 1
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	pe.e = e
+	atomic.StoreInt32(&pe.attrs, attrs)
+	ev := policyEvent{version: version, typ: eventReload, time: time.Now()}
+	if version != pe.Version()+1 {
+		// the history before a jump in versions no longer leads here
+		pe.feed.reset()
+	}
+	if version <= pe.Version() {
+		pe.generation++
+	}
+	atomic.StoreUint64(&pe.version, version)
+	pe.feed.publish(ev)
+}
+
+// attributeModel reports whether the model's request definition uses
+// attribute fields.
+func attributeModel(m model.Model) bool {
+	for _, token := range m["r"]["r"].Tokens {
+		if strings.HasSuffix(token, "_attrs") {
+			return true
+		}
+	}
+	return false
+}
+
+// changed reports whether either file was modified since it was last seen.
+// A failed reload is not retried until the files change again.
+func (pe *policyEnforcer) changed() bool {
+	modelMod, err := modTime(pe.modelPath)
+	if err != nil {
+		return false
+	}
+	policyMod, err := modTime(pe.policyPath)
+	if err != nil {
+		return false
+	}
+	if modelMod.Equal(pe.modelMod) && policyMod.Equal(pe.policyMod) {
+		return false
+	}
+	pe.modelMod, pe.policyMod = modelMod, policyMod
+	return true
+}
+
+// watch polls the model and policy files every interval until stop is closed.
+func (pe *policyEnforcer) watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			pe.writeMu.Lock()
+			if pe.changed() {
+				if err := pe.reload(); err != nil {
+					log.Println("policy reload failed, keeping previous policy:", err)
+				} else {
+					log.Println("policy reloaded from", pe.modelPath, pe.policyPath)
+				}
+			}
+			pe.writeMu.Unlock()
+		}
+	}
+}
+
+// request is one access request as supplied by a Check call.
+type request struct {
+	sub, dom, obj, act string
+
+	subAttrs, objAttrs, envAttrs attributes
+}
+
+// Version returns the current policy version.
+func (pe *policyEnforcer) Version() uint64 {
+	return atomic.LoadUint64(&pe.version)
+}
+
+// stamp returns the generation and version of the live policy, which
+// together identify it across leader restarts.
+func (pe *policyEnforcer) stamp() (uint64, uint64) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	return pe.generation, pe.Version()
+}
+
+// usesAttributes reports whether decisions may depend on request attributes
+// or the time.
+func (pe *policyEnforcer) usesAttributes() bool {
+	return atomic.LoadInt32(&pe.attrs) == 1
+}
+
+func (pe *policyEnforcer) Enforce(r request) (bool, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	return enforce(pe.e, r)
+}
+
+// Decide enforces the request and reports the policy version it was
+// evaluated against. With withRule the deciding rule is looked up as well,
+// at the cost of an explanation.
+func (pe *policyEnforcer) Decide(r request, withRule bool) (decision, error) {
+	if withRule {
+		ex, err := pe.Explain(r)
+		if err != nil {
+			return decision{}, err
+		}
+		return ex.decision(), nil
+	}
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	res, err := enforce(pe.e, r)
+	return decision{res: res, generation: pe.generation, version: pe.Version()}, err
+}
+
+// BatchEnforce evaluates every request against the same policy snapshot;
+// no reload or edit can land between two decisions of one batch. It returns
+// the policy version of that snapshot.
+func (pe *policyEnforcer) BatchEnforce(reqs []request) ([]bool, uint64, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	res := make([]bool, len(reqs))
+	for i, r := range reqs {
+		ok, err := enforce(pe.e, r)
+		if err != nil {
+			return nil, 0, fmt.Errorf("request %d: %v", i, err)
+		}
+		res[i] = ok
+	}
+	return res, pe.Version(), nil
+}
+
+func enforce(e *casbin.Enforcer, r request) (bool, error) {
+	vals, err := requestValues(e.GetModel(), r)
+	if err != nil {
+		return false, err
+	}
+	return e.EnforceSafe(vals...)
+}
+
+// requestValues orders the request's fields the way the model's request
+// definition expects them, e.g. "sub, dom, obj, act" for a domain model or
+// "sub, obj, act, sub_attrs, obj_attrs, env_attrs" for an ABAC model.
+func requestValues(m model.Model, r request) ([]interface{}, error) {
+	tokens := m["r"]["r"].Tokens
+	vals := make([]interface{}, len(tokens))
+	hasDom := false
+	for i, token := range tokens {
+		switch token {
+		case "r_sub":
+			vals[i] = r.sub
+		case "r_dom":
+			if r.dom == "" {
+				return nil, &errInvalidArgument{"the model requires a domain"}
+			}
+			vals[i] = r.dom
+			hasDom = true
+		case "r_obj":
+			vals[i] = r.obj
+		case "r_act":
+			vals[i] = r.act
+		case "r_sub_attrs":
+			vals[i] = r.subAttrs
+		case "r_obj_attrs":
+			vals[i] = r.objAttrs
+		case "r_env_attrs":
+			vals[i] = r.envAttrs
+		default:
+			return nil, &errInvalidArgument{fmt.Sprintf("unsupported request field %q", token)}
+		}
+	}
+	if r.dom != "" && !hasDom {
+		return nil, &errInvalidArgument{"the model has no domains"}
+	}
+	return vals, nil
+}
+
+// addPolicy adds a rule to section "p" or "g" of the live policy and persists
+// it. It reports false if the rule already exists. A non-empty
+// dom scopes the rule to that domain; params then omit the domain field.
+// The change is recorded as a revision by author.
+func (pe *policyEnforcer) addPolicy(sec, dom string, params []string, author string) (bool, error) {
+	return pe.updatePolicy(sec, dom, params, true, author)
+}
+
+// removePolicy removes a rule from section "p" or "g" of the live policy and
+// persists the removal. It reports false if the rule does not exist.
+func (pe *policyEnforcer) removePolicy(sec, dom string, params []string, author string) (bool, error) {
+	return pe.updatePolicy(sec, dom, params, false, author)
+}
+
+func (pe *policyEnforcer) updatePolicy(sec, dom string, params []string, add bool, author string) (bool, error) {
+	pe.writeMu.Lock()
+	defer pe.writeMu.Unlock()
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	rule, err := withDomain(pe.e.GetModel(), sec, dom, params)
+	if err != nil {
+		return false, err
+	}
+	if err := checkRule(pe.e, sec, rule); err != nil {
+		return false, err
+	}
+	ok, err := applyRule(pe.e, sec, rule, add)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := persistRule(pe.e, pe.adapter, sec, rule, add); err != nil {
+		// keep memory consistent with the store we failed to write
+		_, _ = applyRule(pe.e, sec, rule, !add)
+		return false, fmt.Errorf("save policy %s: %v", pe.policyPath, err)
+	}
+	if pe.history != nil {
+		rev := policyRevision{Author: author, Kind: revisionEdit}
+		if add {
+			rev.Added = []ruleChange{{Sec: sec, Rule: rule}}
+		} else {
+			rev.Removed = []ruleChange{{Sec: sec, Rule: rule}}
+		}
+		if _, err := pe.history.record(rev); err != nil {
+			// keep the policy consistent with the history we failed to write
+			_, _ = applyRule(pe.e, sec, rule, !add)
+			_ = persistRule(pe.e, pe.adapter, sec, rule, !add)
+			return false, fmt.Errorf("record revision: %v", err)
+		}
+	}
+	typ := eventRemove
+	if add {
+		typ = eventAdd
+	}
+	version := atomic.AddUint64(&pe.version, 1)
+	pe.feed.publish(policyEvent{version: version, typ: typ, sec: sec, rule: rule, time: time.Now()})
+	// the watcher does not need to reload our own write
+	if mod, err := modTime(pe.policyPath); err == nil {
+		pe.policyMod = mod
+	}
+	return true, nil
+}
+
+// withDomain inserts a non-empty dom into params at the model's domain
+// position.
+func withDomain(m model.Model, sec, dom string, params []string) ([]string, error) {
+	if dom == "" {
+		return params, nil
+	}
+	i, err := domainIndex(m, sec)
+	if err != nil {
+		return nil, err
+	}
+	if i > len(params) {
+		return nil, &errInvalidArgument{fmt.Sprintf("%s rule needs %d values before the domain", sec, i)}
+	}
+	rule := make([]string, 0, len(params)+1)
+	return append(append(append(rule, params[:i]...), dom), params[i:]...), nil
+}
+
+// persistRule writes a single rule change through the adapter. Adapters that
+// cannot store single rules, like casbin's CSV file adapter, rewrite the
+// whole policy instead.
+func persistRule(e *casbin.Enforcer, adapter persist.Adapter, sec string, rule []string, add bool) error {
+	var err error
+	if add {
+		err = adapter.AddPolicy(sec, sec, rule)
+	} else {
+		err = adapter.RemovePolicy(sec, sec, rule)
+	}
+	if err != nil && err.Error() == "not implemented" {
+		return adapter.SavePolicy(e.GetModel())
+	}
+	return err
+}
+
+func applyRule(e *casbin.Enforcer, sec string, rule []string, add bool) (bool, error) {
+	params := make([]interface{}, len(rule))
+	for i, v := range rule {
+		params[i] = v
+	}
+	switch {
+	case sec == "p" && add:
+		return e.AddPolicySafe(params...)
+	case sec == "p":
+		return e.RemovePolicySafe(params...)
+	case add:
+		return e.AddGroupingPolicySafe(params...)
+	default:
+		return e.RemoveGroupingPolicySafe(params...)
+	}
+}
+
+// errInvalidArgument is returned when a request or rule does not match the
+// model's definitions.
+type errInvalidArgument struct {
+	msg string
+}
+
+func (e *errInvalidArgument) Error() string {
+	return e.msg
+}
+
+// domainIndex returns the position of the domain field in rules of section
+// "p" (the p.dom token) or "g" (the third field of "g = _, _, _").
+func domainIndex(m model.Model, sec string) (int, error) {
+	if ast, ok := m[sec][sec]; ok {
+		// a third field of "g" rules is a domain only if requests have one;
+		// otherwise it is a validity field, see schedule.go
+		if sec == "g" && strings.Count(ast.Value, "_") >= 3 && hasToken(m, "r", "r_dom") {
+			return 2, nil
+		}
+		for i, token := range ast.Tokens {
+			if token == "p_dom" {
+				return i, nil
+			}
+		}
+	}
+	return 0, &errInvalidArgument{fmt.Sprintf("the model has no domain in %q rules", sec)}
+}
+
+// hasToken reports whether the definition of section sec has token, e.g.
+// "r_dom".
+func hasToken(m model.Model, sec, token string) bool {
+	if ast, ok := m[sec][sec]; ok {
+		for _, t := range ast.Tokens {
+			if t == token {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkRule rejects rules whose arity does not match the model, which would
+// otherwise make every later Enforce call fail.
+func checkRule(e *casbin.Enforcer, sec string, rule []string) error {
+	ast, ok := e.GetModel()[sec][sec]
+	if !ok {
+		return &errInvalidArgument{fmt.Sprintf("model has no %q definition", sec)}
+	}
+	want := len(ast.Tokens)
+	if sec == "g" {
+		want = strings.Count(ast.Value, "_")
+	}
+	if len(rule) != want {
+		return &errInvalidArgument{fmt.Sprintf("%s rule needs %d values, got %d", sec, want, len(rule))}
+	}
+	for _, v := range rule {
+		if strings.TrimSpace(v) == "" || strings.Contains(v, ",") {
+			return &errInvalidArgument{fmt.Sprintf("invalid value %q in %s rule", v, sec)}
+		}
+	}
+	for i, token := range ast.Tokens {
+		if token == "p_eft" && rule[i] != "allow" && rule[i] != "deny" {
+			return &errInvalidArgument{fmt.Sprintf("effect must be allow or deny, got %q", rule[i])}
+		}
+	}
+	if i, ok := whenIndex(e.GetModel(), sec); ok {
+		if _, err := parseSchedule(rule[i]); err != nil {
+			return &errInvalidArgument{err.Error()}
+		}
+	}
+	return nil
+}
+
+// policies returns the rules of section "p" or "g" matching the filter and,
+// if dom is not empty, belonging to that domain, and the policy version they
+// were read from.
+func (pe *policyEnforcer) policies(sec, dom string, fieldIndex int, fieldValues ...string) ([][]string, uint64, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	var rules [][]string
+	if sec == "p" {
+		rules = pe.e.GetFilteredPolicy(fieldIndex, fieldValues...)
+	} else {
+		rules = pe.e.GetFilteredGroupingPolicy(fieldIndex, fieldValues...)
+	}
+	version := pe.Version()
+	if dom == "" {
+		return rules, version, nil
+	}
+	i, err := domainIndex(pe.e.GetModel(), sec)
+	if err != nil {
+		return nil, 0, err
+	}
+	var scoped [][]string
+	for _, rule := range rules {
+		if i < len(rule) && rule[i] == dom {
+			scoped = append(scoped, rule)
+		}
+	}
+	return scoped, version, nil
+}
+
+func modTime(path string) (time.Time, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime(), nil
+}
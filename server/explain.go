@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Knetic/govaluate"
+	"github.com/casbin/casbin"
+	"github.com/casbin/casbin/model"
+	"github.com/casbin/casbin/util"
+)
+
+// explanation describes how a decision was reached.
+type explanation struct {
+	res    bool
+	effect string
+	rules  []matchedRule
+	// generation and version of the policy, see policyEnforcer
+	generation, version uint64
+}
+
+// matchedRule is a policy line whose matcher evaluated to true.
+type matchedRule struct {
+	index    int
+	rule     []string
+	eft      string
+	rolePath [][]string
+	decisive bool
+}
+
+// Explain enforces the request and reports every policy line that matched it,
+// the grouping rules linking the subject to each line's subject, and which
+// line decided the outcome under the model's policy effect.
+func (pe *policyEnforcer) Explain(r request) (*explanation, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	ex, err := explain(pe.e, r)
+	if err != nil {
+		return nil, err
+	}
+	ex.generation, ex.version = pe.generation, pe.Version()
+	return ex, nil
+}
+
+// BatchExplain explains every request against the same policy snapshot.
+func (pe *policyEnforcer) BatchExplain(reqs []request) ([]*explanation, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	exs := make([]*explanation, len(reqs))
+	for i, r := range reqs {
+		ex, err := explain(pe.e, r)
+		if err != nil {
+			return nil, fmt.Errorf("request %d: %v", i, err)
+		}
+		ex.generation, ex.version = pe.generation, pe.Version()
+		exs[i] = ex
+	}
+	return exs, nil
+}
+
+// decision summarizes the explanation with the rule that decided the
+// outcome, if any.
+func (ex *explanation) decision() decision {
+	d := decision{res: ex.res, generation: ex.generation, version: ex.version}
+	for _, r := range ex.rules {
+		if r.decisive {
+			d.rule = r.rule
+			break
+		}
+	}
+	return d
+}
+
+func explain(e *casbin.Enforcer, r request) (*explanation, error) {
+	m := e.GetModel()
+	rvals, err := requestValues(m, r)
+	if err != nil {
+		return nil, err
+	}
+	res, err := e.EnforceSafe(rvals...)
+	if err != nil {
+		return nil, err
+	}
+	ex := &explanation{res: res, effect: m["e"]["e"].Value}
+	var dom []string
+	if _, err := domainIndex(m, "g"); err == nil && r.dom != "" {
+		dom = []string{r.dom}
+	}
+
+	expr, err := govaluate.NewEvaluableExpressionWithFunctions(m["m"]["m"].Value, matcherFunctions(m))
+	if err != nil {
+		return nil, err
+	}
+	params := matcherParams{r: m["r"]["r"].Tokens, rvals: rvals, p: m["p"]["p"].Tokens}
+	for i, pvals := range m["p"]["p"].Policy {
+		params.pvals = pvals
+		out, err := expr.Eval(params)
+		if err != nil {
+			return nil, fmt.Errorf("policy line %d: %v", i, err)
+		}
+		if !matched(out) {
+			continue
+		}
+		mr := matchedRule{index: i, rule: pvals, eft: ruleEffect(params)}
+		if psub, err := params.Get("p_sub"); err == nil && psub != r.sub {
+			mr.rolePath = rolePath(m, r.sub, psub.(string), dom...)
+		}
+		ex.rules = append(ex.rules, mr)
+	}
+	markDecisive(ex)
+	return ex, nil
+}
+
+// matcherFunctions returns the functions available to the model's matcher,
+// the same set the enforcer builds for every Enforce call.
+func matcherFunctions(m model.Model) map[string]govaluate.ExpressionFunction {
+	functions := make(map[string]govaluate.ExpressionFunction)
+	for key, function := range model.LoadFunctionMap() {
+		functions[key] = function
+	}
+	for key, function := range customFunctions {
+		functions[key] = function
+	}
+	functions["gActive"] = groupActiveFunc(m)
+	for key, ast := range m["g"] {
+		functions[key] = util.GenerateGFunction(ast.RM)
+	}
+	return functions
+}
+
+func matched(out interface{}) bool {
+	switch v := out.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+func ruleEffect(params matcherParams) string {
+	for i, token := range params.p {
+		if token == "p_eft" {
+			return params.pvals[i]
+		}
+	}
+	return "allow"
+}
+
+// markDecisive flags the matched rule that determined the result, following
+// the effects supported by casbin's default effector.
+func markDecisive(ex *explanation) {
+	first := func(eft string) {
+		for i := range ex.rules {
+			if eft == "" || ex.rules[i].eft == eft {
+				ex.rules[i].decisive = true
+				return
+			}
+		}
+	}
+	switch ex.effect {
+	case "priority(p_eft) || deny":
+		first("")
+	case "!some(where (p_eft == deny))":
+		if !ex.res {
+			first("deny")
+		}
+	default:
+		if ex.res {
+			first("allow")
+		} else {
+			first("deny")
+		}
+	}
+}
+
+// rolePath returns the shortest chain of "g" rules through which sub inherits
+// role, or nil if there is none. With a domain only rules of that domain count.
+func rolePath(m model.Model, sub, role string, domain ...string) [][]string {
+	ast, ok := m["g"]["g"]
+	if !ok {
+		return nil
+	}
+	prev := map[string][]string{sub: nil}
+	queue := []string{sub}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if name == role {
+			var path [][]string
+			for r := prev[role]; r != nil; r = prev[r[0]] {
+				path = append([][]string{r}, path...)
+			}
+			return path
+		}
+		for _, rule := range ast.Policy {
+			if len(rule) < 2 || rule[0] != name {
+				continue
+			}
+			if len(domain) > 0 && (len(rule) < 3 || rule[2] != domain[0]) {
+				continue
+			}
+			if _, seen := prev[rule[1]]; !seen {
+				prev[rule[1]] = rule
+				queue = append(queue, rule[1])
+			}
+		}
+	}
+	return nil
+}
+
+// matcherParams resolves r_* and p_* identifiers for govaluate.
+type matcherParams struct {
+	r     []string
+	rvals []interface{}
+	p     []string
+	pvals []string
+}
+
+func (mp matcherParams) Get(name string) (interface{}, error) {
+	for i, token := range mp.r {
+		if token == name && i < len(mp.rvals) {
+			return mp.rvals[i], nil
+		}
+	}
+	for i, token := range mp.p {
+		if token == name && i < len(mp.pvals) {
+			return mp.pvals[i], nil
+		}
+	}
+	return nil, errors.New("No parameter '" + name + "' found.")
+}
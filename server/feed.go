@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// policy event types
+const (
+	eventReload = "reload"
+	eventAdd    = "add"
+	eventRemove = "remove"
+)
+
+// policyEvent is one change of the live policy. version is the policy
+// version the change produced.
+type policyEvent struct {
+	version uint64
+	typ     string
+	sec     string
+	rule    []string
+	time    time.Time
+}
+
+// policyFeed keeps the most recent policy events so watchers can resume
+// from a policy version they have already seen, and fans new events out to them.
+type policyFeed struct {
+	size int
+
+	mu      sync.Mutex
+	events  []policyEvent
+	version uint64
+	subs    map[chan policyEvent]struct{}
+}
+
+func newPolicyFeed(size int) *policyFeed {
+	return &policyFeed{size: size, subs: make(map[chan policyEvent]struct{})}
+}
+
+// publish records ev and delivers it to all watchers. A watcher that has
+// fallen too far behind is dropped; its channel is closed.
+func (f *policyFeed) publish(ev policyEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, ev)
+	if len(f.events) > f.size {
+		f.events = f.events[len(f.events)-f.size:]
+	}
+	f.version = ev.version
+	for ch := range f.subs {
+		select {
+		case ch <- ev:
+		default:
+			delete(f.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// reset forgets all events and disconnects all watchers, which then have to
+// relist the policy.
+func (f *policyFeed) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = nil
+	for ch := range f.subs {
+		delete(f.subs, ch)
+		close(ch)
+	}
+}
+
+// subscribe returns the events after policy version from and a channel
+// carrying the following ones. A zero from starts at the current version. cancel
+// must be called when the watcher goes away.
+func (f *policyFeed) subscribe(from uint64) (backlog []policyEvent, ch <-chan policyEvent, cancel func(), err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if from == 0 {
+		from = f.version
+	}
+	if from > f.version {
+		return nil, nil, nil, fmt.Errorf("policy version %d is newer than the current version %d; the server may have restarted, relist and watch again", from, f.version)
+	}
+	if from < f.version && (len(f.events) == 0 || f.events[0].version > from+1) {
+		return nil, nil, nil, fmt.Errorf("policy version %d is no longer available; relist and watch from the current version %d", from, f.version)
+	}
+	for _, ev := range f.events {
+		if ev.version > from {
+			backlog = append(backlog, ev)
+		}
+	}
+
+	c := make(chan policyEvent, 64)
+	f.subs[c] = struct{}{}
+	cancel = func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subs[c]; ok {
+			delete(f.subs, c)
+			close(c)
+		}
+	}
+	return backlog, c, cancel, nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// The admin API is governed by a meta-policy, a second model and policy
+// that decide which callers may "read", "add" or "remove" rules about which
+// objects in which domains, e.g. with server/admin_model.conf
+//
+//	p, team-a-lead, *, data1*, *
+//	g, alice, team-a-lead
+//
+// lets alice manage every rule whose object starts with data1. The object of
+// a "p" rule is its obj field, the object of a "g" rule is its role. Rules
+// without a domain are checked against domain "*". Reading the whole policy
+// at once, like WatchPolicy, GetPolicySnapshot, the revision history,
+// simulations and replication do, needs "read" on object "*" in domain "*",
+// and RollbackPolicy needs "rollback" there. Role lookups and ExplainCheck
+// reveal rules too and need "read" on the role or the requested object;
+// lookups listing roles or permissions only return those the caller may
+// read. Check and BatchCheck, which return bare decisions, are not governed.
+
+// governedMethods maps admin RPCs to the meta-policy action they need.
+var governedMethods = map[string]string{
+	"/AccessControl/AddPolicy":                     "add",
+	"/AccessControl/RemovePolicy":                  "remove",
+	"/AccessControl/AddGroupingPolicy":             "add",
+	"/AccessControl/RemoveGroupingPolicy":          "remove",
+	"/AccessControl/ListPolicies":                  "read",
+	"/AccessControl/ListGroupingPolicies":          "read",
+	"/AccessControl/ExplainCheck":                  "read",
+	"/AccessControl/GetRolesForUser":               "read",
+	"/AccessControl/GetImplicitRolesForUser":       "read",
+	"/AccessControl/GetImplicitPermissionsForUser": "read",
+	"/AccessControl/GetUsersForRole":               "read",
+	"/AccessControl/HasRoleForUser":                "read",
+	"/AccessControl/WhoCan":                        "read",
+	"/AccessControl/WatchPolicy":                   "read",
+	"/AccessControl/GetPolicySnapshot":             "read",
+	"/AccessControl/ListPolicyRevisions":           "read",
+	"/AccessControl/GetPolicyRevision":             "read",
+	"/AccessControl/RollbackPolicy":                "rollback",
+	"/AccessControl/SimulatePolicyChange":          "read",
+}
+
+// governor enforces the meta-policy on the admin RPCs of the policy
+// enforced by pe.
+type governor struct {
+	pe   *policyEnforcer
+	meta *policyEnforcer
+}
+
+// allowed reports whether caller may act on rules about obj in dom.
+func (g *governor) allowed(caller, act, dom, obj string) (bool, error) {
+	if dom == "" {
+		dom = "*"
+	}
+	return g.meta.Enforce(request{sub: caller, dom: dom, obj: obj, act: act})
+}
+
+// authorize checks a unary admin call before it runs. Calls returning
+// rules are checked per rule afterwards by filter.
+func (g *governor) authorize(caller, method, act string, req interface{}) error {
+	var dom, obj string
+	switch req := req.(type) {
+	case *proto.Policy:
+		sec := "p"
+		if strings.HasSuffix(method, "GroupingPolicy") {
+			sec = "g"
+		}
+		var err error
+		if dom, obj, err = g.pe.ruleTarget(sec, req.GetDom(), req.GetParams()); err != nil {
+			return statusError(err)
+		}
+	case *proto.WhoCanReq:
+		dom, obj = req.GetDom(), req.GetObj()
+	case *proto.AccessControlReq:
+		dom, obj = req.GetDom(), req.GetObj()
+	case *proto.RoleReq:
+		switch method {
+		case "/AccessControl/GetRolesForUser", "/AccessControl/GetImplicitRolesForUser", "/AccessControl/GetImplicitPermissionsForUser":
+			// lookups by user, checked per result by filter
+			return nil
+		}
+		dom, obj = req.GetDom(), req.GetRole()
+	case *proto.PolicyFilter:
+		return nil
+	case *proto.SnapshotReq, *proto.WatchPolicyReq, *proto.RevisionFilter, *proto.RevisionReq, *proto.SimulateReq:
+		dom, obj = "*", "*"
+	default:
+		return nil
+	}
+	if dom == "" {
+		dom = "*"
+	}
+	ok, err := g.allowed(caller, act, dom, obj)
+	if err != nil {
+		return status.Errorf(codes.Internal, "meta-policy: %v", err)
+	}
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "%s may not %s rules for %s in domain %s", caller, act, obj, dom)
+	}
+	return nil
+}
+
+// filter drops the rules of a PolicyList, and the roles of a user's
+// RoleList, the caller may not read.
+func (g *governor) filter(caller, method string, req, resp interface{}) (interface{}, error) {
+	if roles, ok := resp.(*proto.RoleList); ok && method != "/AccessControl/GetUsersForRole" {
+		dom := req.(*proto.RoleReq).GetDom()
+		if dom == "" {
+			dom = "*"
+		}
+		visible := make([]string, 0, len(roles.GetNames()))
+		for _, role := range roles.GetNames() {
+			ok, err := g.allowed(caller, "read", dom, role)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "meta-policy: %v", err)
+			}
+			if ok {
+				visible = append(visible, role)
+			}
+		}
+		return &proto.RoleList{Names: visible}, nil
+	}
+	list, ok := resp.(*proto.PolicyList)
+	if !ok {
+		return resp, nil
+	}
+	sec := "p"
+	if method == "/AccessControl/ListGroupingPolicies" {
+		sec = "g"
+	}
+	visible := make([]*proto.Policy, 0, len(list.GetPolicies()))
+	for _, p := range list.GetPolicies() {
+		dom, obj, err := g.pe.ruleTarget(sec, "", p.GetParams())
+		if err != nil {
+			return nil, statusError(err)
+		}
+		ok, err := g.allowed(caller, "read", dom, obj)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "meta-policy: %v", err)
+		}
+		if ok {
+			visible = append(visible, p)
+		}
+	}
+	return &proto.PolicyList{Policies: visible, PolicyVersion: list.GetPolicyVersion()}, nil
+}
+
+func (g *governor) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	act, ok := governedMethods[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+	caller := identityFromContext(ctx)
+	if caller == "" {
+		return nil, status.Error(codes.Unauthenticated, "the admin API requires a client certificate or bearer token")
+	}
+	if err := g.authorize(caller, info.FullMethod, act, req); err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return g.filter(caller, info.FullMethod, req, resp)
+}
+
+func (g *governor) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	act, ok := governedMethods[info.FullMethod]
+	if !ok {
+		return handler(srv, ss)
+	}
+	caller := identityFromContext(ss.Context())
+	if caller == "" {
+		return status.Error(codes.Unauthenticated, "the admin API requires a client certificate or bearer token")
+	}
+	// the only governed stream, WatchPolicy, carries every change
+	if err := g.authorize(caller, info.FullMethod, act, &proto.WatchPolicyReq{}); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// ruleTarget returns the domain and object a rule is about, see governor.
+// A non-empty dom is inserted into params as for addPolicy.
+func (pe *policyEnforcer) ruleTarget(sec, dom string, params []string) (string, string, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	m := pe.e.GetModel()
+	rule, err := withDomain(m, sec, dom, params)
+	if err != nil {
+		return "", "", err
+	}
+	field := func(i int) string {
+		if i < 0 || i >= len(rule) {
+			return ""
+		}
+		return rule[i]
+	}
+	if i, err := domainIndex(m, sec); err == nil {
+		dom = field(i)
+	}
+	if sec == "g" {
+		return dom, field(1), nil
+	}
+	obj := -1
+	if ast, ok := m["p"]["p"]; ok {
+		for i, token := range ast.Tokens {
+			if token == "p_obj" {
+				obj = i
+			}
+		}
+	}
+	return dom, field(obj), nil
+}
+
+// chainUnary runs the interceptors in order before the handler.
+func chainUnary(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, h)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// chainStream runs the interceptors in order before the handler.
+func chainStream(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, h)
+			}
+		}
+		return next(srv, ss)
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bufio"
+	proto "casbinsvr/proto"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/casbin/casbin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Every change of a policy the server owns is recorded as a numbered
+// revision in a JSON lines file next to the policy, <policy>.history unless
+// set with -history. An edit through the admin API records the rule it added
+// or removed; loading changed files and rolling back record the complete
+// resulting policy as well as its difference to the one before, so the
+// policy of any revision can be rebuilt from the last complete one. Revision
+// numbers carry on across restarts, unlike the policy version, which starts
+// at 1 whenever the server starts. Followers keep no history.
+
+// revision kinds
+const (
+	revisionLoad     = "load"
+	revisionEdit     = "edit"
+	revisionRollback = "rollback"
+)
+
+// ruleChange is a rule added to or removed from section "p" or "g".
+type ruleChange struct {
+	Sec  string   `json:"sec"`
+	Rule []string `json:"rule"`
+}
+
+// ruleSet is a complete policy.
+type ruleSet struct {
+	P [][]string `json:"p"`
+	G [][]string `json:"g"`
+}
+
+// policyRevision is one line of the history file. Author is the caller that
+// made an edit or rollback and empty for loads from the store.
+type policyRevision struct {
+	Revision   uint64       `json:"revision"`
+	Time       time.Time    `json:"time"`
+	Author     string       `json:"author,omitempty"`
+	Kind       string       `json:"kind"`
+	RollbackTo uint64       `json:"rollback_to,omitempty"`
+	Added      []ruleChange `json:"added,omitempty"`
+	Removed    []ruleChange `json:"removed,omitempty"`
+	// Policy is the complete policy after a load or rollback
+	Policy *ruleSet `json:"policy,omitempty"`
+}
+
+// policyHistory appends revisions to the history file and keeps them in
+// memory for lookups.
+type policyHistory struct {
+	mu        sync.Mutex
+	f         *os.File
+	revisions []policyRevision
+}
+
+// openHistory opens or creates the history file at path.
+func openHistory(path string) (*policyHistory, error) {
+	h := &policyHistory{}
+	if f, err := os.Open(path); err == nil {
+		err = h.read(path, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	h.f = f
+	return h, nil
+}
+
+func (h *policyHistory) read(path string, f *os.File) error {
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var rev policyRevision
+		if err := json.Unmarshal(scanner.Bytes(), &rev); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if n := len(h.revisions); n > 0 && rev.Revision != h.revisions[n-1].Revision+1 {
+			return fmt.Errorf("%s:%d: revision %d follows %d", path, line, rev.Revision, h.revisions[n-1].Revision)
+		}
+		h.revisions = append(h.revisions, rev)
+	}
+	return scanner.Err()
+}
+
+// record numbers rev as the next revision and writes it.
+func (h *policyHistory) record(rev policyRevision) (policyRevision, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rev.Revision = 1
+	if n := len(h.revisions); n > 0 {
+		rev.Revision = h.revisions[n-1].Revision + 1
+	}
+	rev.Time = time.Now().UTC()
+	b, err := json.Marshal(rev)
+	if err != nil {
+		return policyRevision{}, err
+	}
+	if _, err := h.f.Write(append(b, '\n')); err != nil {
+		return policyRevision{}, err
+	}
+	h.revisions = append(h.revisions, rev)
+	return rev, nil
+}
+
+// list returns up to limit revisions, newest first; a zero limit returns all.
+func (h *policyHistory) list(limit int) []policyRevision {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if limit <= 0 || limit > len(h.revisions) {
+		limit = len(h.revisions)
+	}
+	revs := make([]policyRevision, limit)
+	for i := range revs {
+		revs[i] = h.revisions[len(h.revisions)-1-i]
+	}
+	return revs
+}
+
+// get returns revision n.
+func (h *policyHistory) get(n uint64) (policyRevision, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i, ok := h.index(n)
+	if !ok {
+		return policyRevision{}, false
+	}
+	return h.revisions[i], true
+}
+
+// index returns the position of revision n. Callers hold mu.
+func (h *policyHistory) index(n uint64) (int, bool) {
+	if len(h.revisions) == 0 || n < h.revisions[0].Revision {
+		return 0, false
+	}
+	i := int(n - h.revisions[0].Revision)
+	return i, i < len(h.revisions)
+}
+
+// rules rebuilds the complete policy of revision n, or of the latest
+// revision if n is zero, by replaying the edits after the last complete
+// policy recorded before it.
+func (h *policyHistory) rules(n uint64) (p, g [][]string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n == 0 && len(h.revisions) > 0 {
+		n = h.revisions[len(h.revisions)-1].Revision
+	}
+	end, ok := h.index(n)
+	if !ok {
+		return nil, nil, fmt.Errorf("revision %d does not exist", n)
+	}
+	start := end
+	for start >= 0 && h.revisions[start].Policy == nil {
+		start--
+	}
+	if start < 0 {
+		return nil, nil, fmt.Errorf("no complete policy is recorded up to revision %d", n)
+	}
+	rules := map[string][][]string{
+		"p": cloneRules(h.revisions[start].Policy.P),
+		"g": cloneRules(h.revisions[start].Policy.G),
+	}
+	for _, rev := range h.revisions[start+1 : end+1] {
+		// casbin removes the first matching rule and appends new ones
+		for _, c := range rev.Removed {
+			for i, rule := range rules[c.Sec] {
+				if ruleKey(rule) == ruleKey(c.Rule) {
+					rules[c.Sec] = append(rules[c.Sec][:i:i], rules[c.Sec][i+1:]...)
+					break
+				}
+			}
+		}
+		for _, c := range rev.Added {
+			rules[c.Sec] = append(rules[c.Sec], append([]string(nil), c.Rule...))
+		}
+	}
+	return rules["p"], rules["g"], nil
+}
+
+func (h *policyHistory) close() error {
+	return h.f.Close()
+}
+
+func cloneRules(rules [][]string) [][]string {
+	c := make([][]string, len(rules))
+	for i, rule := range rules {
+		c[i] = append([]string(nil), rule...)
+	}
+	return c
+}
+
+func ruleKey(rule []string) string {
+	return strings.Join(rule, "\x00")
+}
+
+// diffPolicy returns the rules added and removed going from the policy
+// (fromP, fromG) to (toP, toG), counting duplicate rules.
+func diffPolicy(fromP, fromG, toP, toG [][]string) (added, removed []ruleChange) {
+	added = append(missingRules("p", toP, fromP), missingRules("g", toG, fromG)...)
+	removed = append(missingRules("p", fromP, toP), missingRules("g", fromG, toG)...)
+	return added, removed
+}
+
+// missingRules returns the rules not matched by one in other.
+func missingRules(sec string, rules, other [][]string) []ruleChange {
+	count := make(map[string]int, len(other))
+	for _, rule := range other {
+		count[ruleKey(rule)]++
+	}
+	var missing []ruleChange
+	for _, rule := range rules {
+		if k := ruleKey(rule); count[k] > 0 {
+			count[k]--
+			continue
+		}
+		missing = append(missing, ruleChange{Sec: sec, Rule: append([]string(nil), rule...)})
+	}
+	return missing
+}
+
+// keepHistory records the changes of pe in the history file at path, and
+// the live policy as a new revision if it differs from the latest recorded
+// one, e.g. because the policy file was edited while the server was down.
+func (pe *policyEnforcer) keepHistory(path string) error {
+	h, err := openHistory(path)
+	if err != nil {
+		return err
+	}
+	pe.writeMu.Lock()
+	defer pe.writeMu.Unlock()
+	p, g, _ := pe.snapshot()
+	rev := policyRevision{Kind: revisionLoad, Policy: &ruleSet{P: p, G: g}}
+	if len(h.revisions) > 0 {
+		lastP, lastG, err := h.rules(0)
+		if err != nil {
+			h.close()
+			return err
+		}
+		rev.Added, rev.Removed = diffPolicy(lastP, lastG, p, g)
+		if len(rev.Added) == 0 && len(rev.Removed) == 0 {
+			pe.history = h
+			return nil
+		}
+	} else {
+		rev.Added, _ = diffPolicy(nil, nil, p, g)
+	}
+	if rev, err = h.record(rev); err != nil {
+		h.close()
+		return err
+	}
+	log.Println("policy recorded as revision", rev.Revision)
+	pe.history = h
+	return nil
+}
+
+// recordLoad records e, freshly loaded from the store, as a new revision if
+// its rules differ from the live policy. Callers hold writeMu.
+func (pe *policyEnforcer) recordLoad(e *casbin.Enforcer) error {
+	if pe.history == nil || pe.e == nil {
+		return nil
+	}
+	oldP, oldG, _ := pe.snapshot()
+	m := e.GetModel()
+	p, g := copyRules(m, "p"), copyRules(m, "g")
+	added, removed := diffPolicy(oldP, oldG, p, g)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	_, err := pe.history.record(policyRevision{Kind: revisionLoad, Added: added, Removed: removed, Policy: &ruleSet{P: p, G: g}})
+	return err
+}
+
+// rollback replaces the live and stored policy with the policy of revision
+// n and records that as a new revision by author. It returns the new
+// revision and policy version.
+func (pe *policyEnforcer) rollback(n uint64, author string) (policyRevision, uint64, error) {
+	pe.writeMu.Lock()
+	defer pe.writeMu.Unlock()
+	p, g, err := pe.history.rules(n)
+	if err != nil {
+		return policyRevision{}, 0, &errInvalidArgument{err.Error()}
+	}
+	e, err := loadEnforcer(pe.modelPath, &snapshotAdapter{p: p, g: g}, pe.effect)
+	if err != nil {
+		return policyRevision{}, 0, err
+	}
+	for sec, rules := range map[string][][]string{"p": p, "g": g} {
+		for _, rule := range rules {
+			if err := checkRule(e, sec, rule); err != nil {
+				return policyRevision{}, 0, &errInvalidArgument{fmt.Sprintf("revision %d does not fit the current model: %v", n, err)}
+			}
+		}
+	}
+
+	oldP, oldG, _ := pe.snapshot()
+	if err := pe.adapter.SavePolicy(e.GetModel()); err != nil {
+		return policyRevision{}, 0, fmt.Errorf("save policy %s: %v", pe.policyPath, err)
+	}
+	added, removed := diffPolicy(oldP, oldG, p, g)
+	rev, err := pe.history.record(policyRevision{
+		Author:     author,
+		Kind:       revisionRollback,
+		RollbackTo: n,
+		Added:      added,
+		Removed:    removed,
+		Policy:     &ruleSet{P: p, G: g},
+	})
+	if err != nil {
+		// keep the store consistent with the history we failed to write
+		pe.mu.RLock()
+		_ = pe.adapter.SavePolicy(pe.e.GetModel())
+		pe.mu.RUnlock()
+		return policyRevision{}, 0, fmt.Errorf("record revision: %v", err)
+	}
+	version := pe.Version() + 1
+	pe.install(e, version)
+	// the watcher does not need to reload our own write
+	if mod, err := modTime(pe.policyPath); err == nil {
+		pe.policyMod = mod
+	}
+	return rev, version, nil
+}
+
+// history returns the policy history of this server.
+func (s *server) history() (*policyHistory, error) {
+	if s.leader != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "this server replicates the policy of %s; its history is kept there", s.leader)
+	}
+	if s.enforcer.history == nil {
+		return nil, status.Error(codes.FailedPrecondition, "this server keeps no policy history")
+	}
+	return s.enforcer.history, nil
+}
+
+// ListPolicyRevisions lists the most recent revisions, newest first, without
+// their changes.
+func (s *server) ListPolicyRevisions(ctx context.Context, req *proto.RevisionFilter) (*proto.RevisionList, error) {
+	h, err := s.history()
+	if err != nil {
+		return nil, err
+	}
+	list := &proto.RevisionList{}
+	for _, rev := range h.list(int(req.GetLimit())) {
+		list.Revisions = append(list.Revisions, toPolicyRevision(rev, false))
+	}
+	return list, nil
+}
+
+// GetPolicyRevision returns a revision and the rules it added and removed.
+func (s *server) GetPolicyRevision(ctx context.Context, req *proto.RevisionReq) (*proto.PolicyRevision, error) {
+	h, err := s.history()
+	if err != nil {
+		return nil, err
+	}
+	rev, ok := h.get(req.GetRevision())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "revision %d does not exist", req.GetRevision())
+	}
+	return toPolicyRevision(rev, true), nil
+}
+
+// RollbackPolicy makes the policy of an earlier revision the live and stored
+// policy in one step. Watchers and followers see a reload.
+func (s *server) RollbackPolicy(ctx context.Context, req *proto.RevisionReq) (*proto.RollbackResp, error) {
+	h, err := s.history()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := h.get(req.GetRevision()); !ok {
+		return nil, status.Errorf(codes.NotFound, "revision %d does not exist", req.GetRevision())
+	}
+	rev, version, err := s.enforcer.rollback(req.GetRevision(), callerFromContext(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	log.Println("policy rolled back to revision", req.GetRevision(), "as revision", rev.Revision, "by", rev.Author)
+	return &proto.RollbackResp{Revision: toPolicyRevision(rev, true), PolicyVersion: version}, nil
+}
+
+func toPolicyRevision(rev policyRevision, withChanges bool) *proto.PolicyRevision {
+	prev := &proto.PolicyRevision{
+		Revision:   rev.Revision,
+		Timestamp:  rev.Time.Unix(),
+		Author:     rev.Author,
+		RollbackTo: rev.RollbackTo,
+	}
+	switch rev.Kind {
+	case revisionEdit:
+		prev.Kind = proto.PolicyRevision_EDIT
+	case revisionRollback:
+		prev.Kind = proto.PolicyRevision_ROLLBACK
+	default:
+		prev.Kind = proto.PolicyRevision_LOAD
+	}
+	if withChanges {
+		prev.Added = toRuleChanges(rev.Added)
+		prev.Removed = toRuleChanges(rev.Removed)
+	}
+	return prev
+}
+
+func toRuleChanges(changes []ruleChange) []*proto.RuleChange {
+	pcs := make([]*proto.RuleChange, len(changes))
+	for i, c := range changes {
+		pcs[i] = &proto.RuleChange{Sec: c.Sec, Policy: &proto.Policy{Params: append([]string(nil), c.Rule...)}}
+	}
+	return pcs
+}
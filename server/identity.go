@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"os"
+	"strings"
+)
+
+// ON_BEHALF_ACT is the action a caller needs on a subject to check requests
+// for it in "on-behalf-of" subject mode, e.g. "p, frontend, alice, check-on-behalf-of".
+const ON_BEHALF_ACT = "check-on-behalf-of"
+
+type identityKey struct{}
+
+// identityFromContext returns the authenticated caller, or "" if the caller
+// did not identify itself.
+func identityFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(identityKey{}).(string)
+	return id
+}
+
+// authenticator establishes who is calling. A bearer token in the
+// "authorization" metadata takes precedence, so a gateway can pass on the
+// token of its own caller; otherwise the verified mutual TLS client
+// certificate names the caller.
+type authenticator struct {
+	// tokens maps the hex SHA-256 of a bearer token to its identity
+	tokens map[string]string
+}
+
+// loadTokens reads a token file. Every line holds an identity and the hex
+// SHA-256 of its token, e.g. "frontend, 9f86d081...", as printed by
+// `printf %s "$TOKEN" | sha256sum`. Lines starting with # are ignored.
+func loadTokens(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, ",")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: want \"identity, sha256\"", path, line)
+		}
+		id, sum := strings.TrimSpace(fields[0]), strings.ToLower(strings.TrimSpace(fields[1]))
+		if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size || id == "" {
+			return nil, fmt.Errorf("%s:%d: want an identity and a hex SHA-256", path, line)
+		}
+		tokens[sum] = id
+	}
+	return tokens, scanner.Err()
+}
+
+// authenticate returns ctx carrying the caller's identity. An unknown bearer
+// token is rejected; a caller without token or certificate stays anonymous.
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token := strings.TrimSpace(values[0])
+			if !strings.HasPrefix(strings.ToLower(token), "bearer ") {
+				return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+			}
+			sum := sha256.Sum256([]byte(strings.TrimSpace(token[len("bearer "):])))
+			id, ok := a.tokens[hex.EncodeToString(sum[:])]
+			if !ok {
+				return nil, status.Error(codes.Unauthenticated, "unknown bearer token")
+			}
+			return context.WithValue(ctx, identityKey{}, id), nil
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			if id := certIdentity(info.State.VerifiedChains[0][0]); id != "" {
+				return context.WithValue(ctx, identityKey{}, id), nil
+			}
+		}
+	}
+	return ctx, nil
+}
+
+// certIdentity names a client certificate by its common name, or else its
+// first URI or DNS name.
+func certIdentity(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	}
+	return ""
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+}
+
+// identityStream is a server stream whose context carries the identity.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// checkSubject applies the server's subject mode to a decision request:
+//
+//	request       the subject is taken from the request as is
+//	caller        the subject is the caller; a request naming anyone else fails
+//	on-behalf-of  the subject defaults to the caller; naming anyone else needs
+//	              the ON_BEHALF_ACT permission on that subject
+//
+// A request left without a subject fails, since matchers comparing the
+// subject with a missing attribute would otherwise compare empty strings.
+func (s *server) checkSubject(ctx context.Context, r *request) error {
+	if s.subjectMode == "request" {
+		if r.sub == "" {
+			return status.Error(codes.InvalidArgument, "the request has no subject")
+		}
+		return nil
+	}
+	caller := identityFromContext(ctx)
+	if caller == "" {
+		return status.Error(codes.Unauthenticated, "a client certificate or bearer token is required")
+	}
+	if r.sub == "" || r.sub == caller {
+		r.sub = caller
+		return nil
+	}
+	if s.subjectMode == "on-behalf-of" {
+		d, err := s.decide(request{sub: caller, dom: r.dom, obj: r.sub, act: ON_BEHALF_ACT})
+		if err != nil {
+			return statusError(err)
+		}
+		if d.res {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "%s may not check access for %s", caller, r.sub)
+}
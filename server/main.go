@@ -1,27 +1,239 @@
 package main
 
 import (
+	"casbinsvr/auditlog"
+	"casbinsvr/boltadapter"
+	"casbinsvr/config"
 	proto "casbinsvr/proto"
+	"casbinsvr/tlsconfig"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"github.com/casbin/casbin"
+	"github.com/casbin/casbin/persist"
+	fileadapter "github.com/casbin/casbin/persist/file-adapter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
 type server struct {
+	enforcer *policyEnforcer
+	// audit records every decision when not nil
+	audit *auditlog.Log
+	// cache answers repeated Checks when not nil
+	cache *decisionCache
+	// leader is the address of the server whose policy this one replicates,
+	// empty if this server owns its policy
+	leader string
+	// subjectMode is "request", "caller" or "on-behalf-of", see checkSubject
+	subjectMode string
+	// stopping is closed when the server shuts down
+	stopping chan struct{}
+	// metricsTargets enables decision metrics by object and action
+	metricsTargets bool
 }
 
 const (
 	MODEL_PATH  = "server/rbac_model.conf"
 	POLICY_PATH = "server/rbac_policy.csv"
+	// meta-policy model governing the admin API, see governance.go
+	ADMIN_MODEL_PATH = "server/admin_model.conf"
+
+	RELOAD_INTERVAL = time.Second
+	// number of policy events kept for WatchPolicy resumption
+	FEED_SIZE = 1024
+	// pause before a follower reconnects to its leader
+	RESYNC_INTERVAL = time.Second
+	// how long shutdown waits for in-flight calls
+	SHUTDOWN_TIMEOUT = 30 * time.Second
+	// most requests one SimulatePolicyChange call decides
+	SIMULATE_LIMIT = 100000
+	// service name reported by the health service
+	SERVICE_NAME = "AccessControl"
+)
+
+var (
+	addr = flag.String("addr", ":50051", "gRPC listen address")
+	// use server/rbac_with_domains_model.conf for multi-tenant deployments
+	// or server/abac_model.conf for attribute-based conditions
+	modelPath  = flag.String("model", MODEL_PATH, "casbin model file")
+	policyPath = flag.String("policy", POLICY_PATH, "casbin policy file, or BoltDB file with -store bolt")
+	// migrate CSV policies into a BoltDB file with: go run ./migrate
+	store = flag.String("store", "csv", "policy store: csv or bolt")
+	// revisions of the policy, see history.go; followers keep none
+	historyPath = flag.String("history", "", "policy revision history file, default <policy>.history")
+	// only for callers that may be trusted with the clock, see schedule.go
+	trustRequestTime = flag.Bool("trust-request-time", false, "decide with the time environment attribute sent by callers instead of the server clock")
+	// rules with a validity field, see schedule.go
+	pruneInterval = flag.Duration("prune-interval", 0, "how often rules whose schedule has ended are removed from the policy store, e.g. 1h; 0 keeps them")
+	// verify the log with: go run ./auditverify -log <file>
+	auditLog = flag.String("audit-log", "", "append every decision to this hash-chained JSON lines file")
+	// cached decisions are also dropped as soon as the policy changes
+	cacheSize = flag.Int("cache-size", 10000, "maximum number of cached Check decisions, 0 disables the cache")
+	cacheTTL  = flag.Duration("cache-ttl", 5*time.Minute, "maximum age of a cached Check decision")
+	// deny rules need a model with an eft column, e.g. server/rbac_with_deny_model.conf
+	effect = flag.String("effect", "", "policy effect overriding the model's: "+effectNames())
+	// followers need the leader's model file; -policy and -store are ignored
+	leader = flag.String("follow", "", "replicate the policy of the server at this address instead of loading it")
+	// certificates are reloaded when the files change; a follower presents
+	// the same certificate to its leader and verifies it with -tls-ca
+	tlsCert = flag.String("tls-cert", "", "PEM certificate to serve TLS with")
+	tlsKey  = flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tlsCA   = flag.String("tls-ca", "", "PEM CA bundle; clients must present a certificate signed by it (mutual TLS)")
+	// callers are identified by bearer token or mutual TLS client certificate
+	tokens      = flag.String("tokens", "", "file of \"identity, sha256 of token\" lines accepted as bearer tokens")
+	subjectMode = flag.String("subject", "request", "subject of checks: request (as sent), caller (the authenticated caller) or on-behalf-of (the caller unless it may "+ON_BEHALF_ACT+" the requested subject)")
+	// e.g. -admin-policy server/admin_policy.csv; without it the admin API is open
+	adminModelPath  = flag.String("admin-model", ADMIN_MODEL_PATH, "casbin model of the meta-policy governing the admin API")
+	adminPolicyPath = flag.String("admin-policy", "", "casbin policy file of the meta-policy governing the admin API")
+	// e.g. -test server/rbac_with_deny_test.yaml, see suite.go
+	testSuite = flag.String("test", "", "run the policy test suite in this YAML file and exit")
+	// Prometheus scrapes http://<metrics-addr>/metrics
+	metricsAddr    = flag.String("metrics-addr", "", "serve Prometheus metrics on this address, e.g. :9095")
+	metricsTargets = flag.Bool("metrics-targets", false, "count decisions by object and action as sent by callers; only for small, trusted object spaces")
 )
 
 func (s *server) Check(ctx context.Context, req *proto.AccessControlReq) (*proto.AccessControlResp, error) {
-	e := casbin.NewEnforcer(MODEL_PATH, POLICY_PATH)
-	sub, obj, act := req.GetSub(), req.GetObj(), req.GetAct()
-	fmt.Println("received:", sub, obj, act)
-	res := e.Enforce(sub, obj, act)
-	return &proto.AccessControlResp{Res: res}, nil
+	defer observeDuration("Check", time.Now())
+	r := toRequest(req)
+	if err := s.checkSubject(ctx, &r); err != nil {
+		return nil, err
+	}
+	d, err := s.decide(r)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	s.observeDecision("Check", r, d.res)
+	if s.audit != nil {
+		if err := s.auditDecision(ctx, "Check", r, d); err != nil {
+			return nil, status.Errorf(codes.Internal, "audit: %v", err)
+		}
+	}
+	return &proto.AccessControlResp{Res: d.res, PolicyVersion: d.version}, nil
+}
+
+// decide answers a Check from the cache when the policy has not changed
+// since the decision was cached.
+func (s *server) decide(r request) (decision, error) {
+	cacheable := s.cache != nil && !s.enforcer.usesAttributes()
+	var key string
+	if cacheable {
+		key = cacheKey(r)
+		generation, version := s.enforcer.stamp()
+		if d, ok := s.cache.get(key, generation, version); ok {
+			cacheRequests.WithLabelValues("hit").Inc()
+			return d, nil
+		}
+		cacheRequests.WithLabelValues("miss").Inc()
+	}
+	d, err := s.enforcer.Decide(r, s.audit != nil)
+	if err != nil {
+		return decision{}, err
+	}
+	if cacheable {
+		s.cache.put(key, d)
+	}
+	return d, nil
+}
+
+func (s *server) ExplainCheck(ctx context.Context, req *proto.AccessControlReq) (*proto.ExplainResp, error) {
+	defer observeDuration("ExplainCheck", time.Now())
+	r := toRequest(req)
+	if err := s.checkSubject(ctx, &r); err != nil {
+		return nil, err
+	}
+	ex, err := s.enforcer.Explain(r)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	s.observeDecision("ExplainCheck", r, ex.res)
+	if s.audit != nil {
+		if err := s.auditDecision(ctx, "ExplainCheck", r, ex.decision()); err != nil {
+			return nil, status.Errorf(codes.Internal, "audit: %v", err)
+		}
+	}
+	resp := &proto.ExplainResp{Res: ex.res, Effect: ex.effect, PolicyVersion: ex.version}
+	for _, r := range ex.rules {
+		resp.Rules = append(resp.Rules, &proto.MatchedRule{
+			Index:    int32(r.index),
+			Policy:   &proto.Policy{Params: append([]string(nil), r.rule...)},
+			Eft:      r.eft,
+			RolePath: toPolicies(r.rolePath),
+			Decisive: r.decisive,
+		})
+	}
+	return resp, nil
+}
+
+func (s *server) BatchCheck(ctx context.Context, req *proto.BatchCheckReq) (*proto.BatchCheckResp, error) {
+	defer observeDuration("BatchCheck", time.Now())
+	reqs := make([]request, len(req.GetReqs()))
+	for i, r := range req.GetReqs() {
+		reqs[i] = toRequest(r)
+		if err := s.checkSubject(ctx, &reqs[i]); err != nil {
+			return nil, err
+		}
+	}
+	var (
+		res     []bool
+		version uint64
+	)
+	if s.audit != nil {
+		exs, err := s.enforcer.BatchExplain(reqs)
+		if err != nil {
+			return nil, statusError(err)
+		}
+		res = make([]bool, len(exs))
+		for i, ex := range exs {
+			if err := s.auditDecision(ctx, "BatchCheck", reqs[i], ex.decision()); err != nil {
+				return nil, status.Errorf(codes.Internal, "audit: %v", err)
+			}
+			res[i], version = ex.res, ex.version
+		}
+	} else {
+		var err error
+		if res, version, err = s.enforcer.BatchEnforce(reqs); err != nil {
+			return nil, statusError(err)
+		}
+	}
+	resps := make([]*proto.AccessControlResp, len(res))
+	for i, r := range res {
+		s.observeDecision("BatchCheck", reqs[i], r)
+		resps[i] = &proto.AccessControlResp{Res: r, PolicyVersion: version}
+	}
+	return &proto.BatchCheckResp{Resps: resps, PolicyVersion: version}, nil
+}
+
+func toRequest(req *proto.AccessControlReq) request {
+	return request{
+		sub:      req.GetSub(),
+		dom:      req.GetDom(),
+		obj:      req.GetObj(),
+		act:      req.GetAct(),
+		subAttrs: toAttributes(req.GetSubAttrs()),
+		objAttrs: toAttributes(req.GetObjAttrs()),
+		envAttrs: envAttributes(req.GetEnvAttrs(), *trustRequestTime),
+	}
+}
+
+// statusError maps enforcer errors onto gRPC status codes.
+func statusError(err error) error {
+	if _, ok := err.(*errInvalidArgument); ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
 }
 
 func (s *server) Echo(ctx context.Context, req *proto.StringMessage) (*proto.StringMessage, error) {
@@ -29,22 +241,252 @@ func (s *server) Echo(ctx context.Context, req *proto.StringMessage) (*proto.Str
 	return &proto.StringMessage{Value: "Hello " + req.Value}, nil
 }
 
+// newAdapter opens the policy store at path. The returned func releases it.
+func newAdapter(store, path string) (persist.Adapter, func(), error) {
+	switch store {
+	case "csv":
+		return fileadapter.NewAdapter(path), func() {}, nil
+	case "bolt":
+		a, err := boltadapter.NewAdapter(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return a, func() { a.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("unknown policy store %q, want csv or bolt", store)
+}
+
+// validateConfig rejects settings the server could only fail on later.
+func validateConfig() error {
+	if _, _, err := net.SplitHostPort(*addr); err != nil {
+		return fmt.Errorf("addr: %v", err)
+	}
+	if _, _, err := net.SplitHostPort(*metricsAddr); *metricsAddr != "" && err != nil {
+		return fmt.Errorf("metrics-addr: %v", err)
+	}
+	switch *store {
+	case "csv", "bolt":
+	default:
+		return fmt.Errorf("unknown policy store %q, want csv or bolt", *store)
+	}
+	switch *subjectMode {
+	case "request", "caller", "on-behalf-of":
+	default:
+		return fmt.Errorf("unknown subject mode %q, want request, caller or on-behalf-of", *subjectMode)
+	}
+	if err := checkEffect(*effect); err != nil {
+		return err
+	}
+	if *pruneInterval < 0 {
+		return errors.New("prune-interval must not be negative")
+	}
+	if *cacheSize < 0 || *cacheTTL <= 0 {
+		return errors.New("cache-size must not be negative and cache-ttl must be positive")
+	}
+	if (*tlsCert == "") != (*tlsKey == "") || *tlsCA != "" && *tlsCert == "" {
+		return errors.New("tls-cert and tls-key go together, and tls-ca needs them")
+	}
+	if *subjectMode != "request" && *tokens == "" && *tlsCA == "" {
+		return fmt.Errorf("subject mode %s needs tokens or tls-ca to identify callers", *subjectMode)
+	}
+	files := []string{*modelPath, *tlsCert, *tlsKey, *tlsCA, *tokens, *adminPolicyPath}
+	if *adminPolicyPath != "" {
+		files = append(files, *adminModelPath)
+	}
+	if *leader == "" && *store == "csv" {
+		files = append(files, *policyPath)
+	}
+	return config.CheckFiles(files...)
+}
+
 func main() {
-	//lis, err := net.Listen("tcp", ":50051")
-	//if err != nil {
-	//	log.Fatalf("failed to listen: %v", err)
-	//}
-	//
-	//fmt.Println("AccessControl Server is starting... no panic means ok!")
-	//s := grpc.NewServer()
-	//proto.RegisterAccessControlServer(s, &server{})
-	//if err := s.Serve(lis); err != nil {
-	//	log.Fatalf("failed to serve: %v", err)
-	//}
-
-	e := casbin.NewEnforcer(MODEL_PATH, POLICY_PATH)
-	sub, obj, act := "alice", "data1", "read"
-	fmt.Println("received:", sub, obj, act)
-	res := e.Enforce(sub, obj, act)
-	fmt.Println("res: ", res)
+	if err := config.Parse("server", validateConfig); err != nil {
+		log.Fatal(err)
+	}
+	if *testSuite != "" {
+		os.Exit(runSuite(*testSuite, *modelPath, *policyPath, *store, *effect))
+	}
+	srv := &server{
+		cache:       newDecisionCache(*cacheSize, *cacheTTL),
+		leader:      *leader,
+		subjectMode: *subjectMode,
+		stopping:    make(chan struct{}),
+
+		metricsTargets: *metricsTargets,
+	}
+	auth := &authenticator{}
+	if *tokens != "" {
+		var err error
+		if auth.tokens, err = loadTokens(*tokens); err != nil {
+			log.Fatalf("failed to load tokens: %v", err)
+		}
+	}
+	files := tlsconfig.Files{Cert: *tlsCert, Key: *tlsKey, CA: *tlsCA}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// calls are rejected until the policy is loaded, so everything after
+	// the ready interceptor may use srv.enforcer
+	ready := make(chan struct{})
+	unary := []grpc.UnaryServerInterceptor{readyUnary(ready), auth.unaryInterceptor}
+	stream := []grpc.StreamServerInterceptor{readyStream(ready), auth.streamInterceptor}
+	var gov *governor
+	if *adminPolicyPath != "" {
+		meta, err := newPolicyEnforcer(*adminModelPath, *adminPolicyPath, fileadapter.NewAdapter(*adminPolicyPath), "")
+		if err != nil {
+			log.Fatalf("failed to load meta-policy: %v", err)
+		}
+		go meta.watch(RELOAD_INTERVAL, stop)
+		gov = &governor{meta: meta}
+		unary = append(unary, gov.unaryInterceptor)
+		stream = append(stream, gov.streamInterceptor)
+	}
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(chainUnary(unary...)),
+		grpc.StreamInterceptor(chainStream(stream...)),
+	}
+	if !files.Empty() {
+		config, err := tlsconfig.Server(files)
+		if err != nil {
+			log.Fatalf("failed to configure TLS: %v", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	}
+	var err error
+	if *auditLog != "" {
+		if srv.audit, err = auditlog.Open(*auditLog); err != nil {
+			log.Fatalf("failed to open audit log: %v", err)
+		}
+		defer srv.audit.Close()
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	fmt.Println("AccessControl Server is starting... no panic means ok!")
+	s := grpc.NewServer(opts...)
+	proto.RegisterAccessControlServer(s, srv)
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthSrv.SetServingStatus(SERVICE_NAME, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(s, healthSrv)
+	reflection.Register(s)
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			log.Fatalf("failed to serve: %v", err)
+		}
+	}()
+	if *metricsAddr != "" {
+		go serveMetrics(*metricsAddr)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	if *leader == "" {
+		adapter, closeAdapter, err := newAdapter(*store, *policyPath)
+		if err != nil {
+			log.Fatalf("failed to open policy store: %v", err)
+		}
+		defer closeAdapter()
+		if srv.enforcer, err = newPolicyEnforcer(*modelPath, *policyPath, adapter, *effect); err != nil {
+			log.Fatalf("failed to load policy: %v", err)
+		}
+		if *historyPath == "" {
+			*historyPath = *policyPath + ".history"
+		}
+		if err := srv.enforcer.keepHistory(*historyPath); err != nil {
+			log.Fatalf("failed to open policy history: %v", err)
+		}
+		defer srv.enforcer.history.close()
+		go srv.enforcer.watch(RELOAD_INTERVAL, stop)
+		if *pruneInterval > 0 {
+			go srv.enforcer.prune(*pruneInterval, stop)
+		}
+	} else {
+		creds := grpc.WithInsecure()
+		if !files.Empty() {
+			config, err := tlsconfig.Client(files, "")
+			if err != nil {
+				log.Fatalf("failed to configure TLS: %v", err)
+			}
+			creds = grpc.WithTransportCredentials(credentials.NewTLS(config))
+		}
+		conn, err := grpc.Dial(*leader, creds)
+		if err != nil {
+			log.Fatalf("did not connect to leader: %v", err)
+		}
+		defer conn.Close()
+		// the empty policy is not served; the leader's snapshot replaces it
+		if srv.enforcer, err = newPolicyEnforcer(*modelPath, "", &snapshotAdapter{}, *effect); err != nil {
+			log.Fatalf("failed to load model: %v", err)
+		}
+		synced := make(chan struct{})
+		go follow(srv.enforcer, proto.NewAccessControlClient(conn), synced, stop)
+		log.Println("waiting for the policy of", *leader)
+		select {
+		case <-synced:
+		case sig := <-signals:
+			log.Println("received", sig, "before the policy was loaded")
+			s.Stop()
+			return
+		}
+	}
+	if gov != nil {
+		gov.pe = srv.enforcer
+	}
+	registerPolicyMetrics(srv.enforcer)
+	close(ready)
+	healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthSrv.SetServingStatus(SERVICE_NAME, healthpb.HealthCheckResponse_SERVING)
+	log.Println("serving on", lis.Addr())
+
+	sig := <-signals
+	log.Println("received", sig, "- draining in-flight calls")
+	healthSrv.Shutdown()
+	close(srv.stopping)
+	drained := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(SHUTDOWN_TIMEOUT):
+		log.Println("calls still running after", SHUTDOWN_TIMEOUT, "- stopping")
+		s.Stop()
+	}
+}
+
+// readyUnary rejects AccessControl calls with Unavailable until ready is
+// closed. Health and reflection calls always pass.
+func readyUnary(ready <-chan struct{}) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkReady(ready, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// readyStream is readyUnary for streaming calls.
+func readyStream(ready <-chan struct{}) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkReady(ready, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func checkReady(ready <-chan struct{}, method string) error {
+	if !strings.HasPrefix(method, "/"+SERVICE_NAME+"/") {
+		return nil
+	}
+	select {
+	case <-ready:
+		return nil
+	default:
+		return status.Error(codes.Unavailable, "the policy is not loaded yet")
+	}
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metrics are exported in the Prometheus text format on -metrics-addr.
+var (
+	decisionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "casbinsvr_decisions_total",
+		Help: "Access decisions by RPC method and result.",
+	}, []string{"method", "allowed"})
+	// one series per object and action sent by callers, so only counted
+	// with -metrics-targets for small object spaces and trusted callers
+	decisionsByTarget = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "casbinsvr_decisions_by_target_total",
+		Help: "Access decisions by object, action and result.",
+	}, []string{"obj", "act", "allowed"})
+	checkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "casbinsvr_check_duration_seconds",
+		Help:    "Latency of decision RPCs.",
+		Buckets: []float64{.00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1},
+	}, []string{"method"})
+	policyReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "casbinsvr_policy_reloads_total",
+		Help: "Policy loads from the store or the leader by policy and result.",
+	}, []string{"policy", "result"})
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "casbinsvr_decision_cache_requests_total",
+		Help: "Decision cache lookups by result, hit or miss.",
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(decisionsTotal, decisionsByTarget, checkDuration, policyReloads, cacheRequests)
+}
+
+// registerPolicyMetrics exports the size and version of the live policy.
+func registerPolicyMetrics(pe *policyEnforcer) {
+	for _, sec := range []string{"p", "g"} {
+		sec := sec
+		prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "casbinsvr_policy_rules",
+			Help:        "Rules in the live policy by section.",
+			ConstLabels: prometheus.Labels{"sec": sec},
+		}, func() float64 {
+			return float64(pe.ruleCount(sec))
+		}))
+	}
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "casbinsvr_policy_version",
+		Help: "Version of the live policy.",
+	}, func() float64 {
+		return float64(pe.Version())
+	}))
+}
+
+// ruleCount returns the number of rules in section "p" or "g".
+func (pe *policyEnforcer) ruleCount(sec string) int {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	if ast, ok := pe.e.GetModel()[sec][sec]; ok {
+		return len(ast.Policy)
+	}
+	return 0
+}
+
+// observeDecision counts a decision of method for r.
+func (s *server) observeDecision(method string, r request, res bool) {
+	allowed := strconv.FormatBool(res)
+	decisionsTotal.WithLabelValues(method, allowed).Inc()
+	if s.metricsTargets {
+		decisionsByTarget.WithLabelValues(r.obj, r.act, allowed).Inc()
+	}
+}
+
+// observeDuration records the latency of a decision RPC started at start.
+func observeDuration(method string, start time.Time) {
+	checkDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// countReload records the outcome of loading the policy at path.
+func countReload(path string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	policyReloads.WithLabelValues(path, result).Inc()
+}
+
+// serveMetrics serves /metrics on addr until the process exits.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	log.Println("metrics on", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("failed to serve metrics: %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/casbin/casbin/model"
+	"io"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// A follower keeps no policy store of its own. It loads the leader's policy
+// from a snapshot, then applies the leader's WatchPolicy events one by one,
+// taking over their policy versions, so that every replica answers from the
+// same numbered policy. Any gap, reload on the leader or broken stream makes
+// it start over from a new snapshot. Versions start again at 1 when the
+// leader restarts.
+//
+// To try it with separate processes, from the repository root and on a copy
+// of the policy, since the leader saves its changes:
+//
+//	go build -o /tmp/casbinsvr ./server
+//	cp server/rbac_with_deny_policy.csv /tmp/policy.csv
+//	/tmp/casbinsvr -addr :50051 -model server/rbac_with_deny_model.conf -policy /tmp/policy.csv
+//	/tmp/casbinsvr -addr :50052 -model server/rbac_with_deny_model.conf -follow localhost:50051
+//	grpcurl -plaintext -d '{"params": ["carol", "data2", "read", "allow"]}' localhost:50051 AccessControl/AddPolicy
+//	grpcurl -plaintext -d '{"sub": "carol", "obj": "data2", "act": "read"}' localhost:50052 AccessControl/Check
+//
+// The Check on the follower answers true at the leader's policy version.
+// The same AddPolicy sent to :50052 fails with FailedPrecondition. After the
+// leader is stopped and started again the follower logs that replication was
+// interrupted and resyncs at version 1. TestReplication covers the same
+// steps in one process.
+
+// snapshot returns copies of all "p" and "g" rules and the policy version
+// they belong to.
+func (pe *policyEnforcer) snapshot() (p, g [][]string, version uint64) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	m := pe.e.GetModel()
+	return copyRules(m, "p"), copyRules(m, "g"), pe.Version()
+}
+
+func copyRules(m model.Model, sec string) [][]string {
+	ast, ok := m[sec][sec]
+	if !ok {
+		return nil
+	}
+	rules := make([][]string, len(ast.Policy))
+	for i, rule := range ast.Policy {
+		rules[i] = append([]string(nil), rule...)
+	}
+	return rules
+}
+
+// restore replaces the live policy with the given rules at the given version.
+func (pe *policyEnforcer) restore(p, g [][]string, version uint64) error {
+	pe.writeMu.Lock()
+	defer pe.writeMu.Unlock()
+	e, err := loadEnforcer(pe.modelPath, &snapshotAdapter{p: p, g: g}, pe.effect)
+	countReload("leader", err)
+	if err != nil {
+		return err
+	}
+	pe.install(e, version)
+	return nil
+}
+
+// replicate applies a rule change made on the leader. The event must carry
+// the policy version right after the current one.
+func (pe *policyEnforcer) replicate(ev policyEvent) error {
+	pe.writeMu.Lock()
+	defer pe.writeMu.Unlock()
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	if ev.version != pe.Version()+1 {
+		return fmt.Errorf("got policy version %d after %d", ev.version, pe.Version())
+	}
+	ok, err := applyRule(pe.e, ev.sec, ev.rule, ev.typ == eventAdd)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("policy version %d: %s rule %v does not apply", ev.version, ev.sec, ev.rule)
+	}
+	atomic.StoreUint64(&pe.version, ev.version)
+	pe.feed.publish(ev)
+	return nil
+}
+
+// follow keeps pe in sync with the leader until stop is closed. ready is
+// closed once the first snapshot has been loaded.
+func follow(pe *policyEnforcer, leader proto.AccessControlClient, ready chan<- struct{}, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+	for {
+		version, err := resync(ctx, pe, leader)
+		if err == nil {
+			if ready != nil {
+				close(ready)
+				ready = nil
+			}
+			err = stream(ctx, pe, leader, version)
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if err == errLeaderReloaded {
+			continue
+		}
+		log.Println("policy replication interrupted, resyncing:", err)
+		select {
+		case <-stop:
+			return
+		case <-time.After(RESYNC_INTERVAL):
+		}
+	}
+}
+
+// resync replaces the live policy with a snapshot from the leader.
+func resync(ctx context.Context, pe *policyEnforcer, leader proto.AccessControlClient) (uint64, error) {
+	snap, err := leader.GetPolicySnapshot(ctx, &proto.SnapshotReq{})
+	if err != nil {
+		return 0, err
+	}
+	if err := pe.restore(fromPolicies(snap.GetPolicies()), fromPolicies(snap.GetGroupingPolicies()), snap.GetPolicyVersion()); err != nil {
+		return 0, err
+	}
+	log.Println("policy replicated at version", snap.GetPolicyVersion())
+	return snap.GetPolicyVersion(), nil
+}
+
+// errLeaderReloaded ends a stream when the leader has replaced its whole
+// policy, which is only carried over by a new snapshot.
+var errLeaderReloaded = errors.New("leader reloaded its policy")
+
+// stream applies the leader's changes after version until the stream ends
+// or an event cannot be applied.
+func stream(ctx context.Context, pe *policyEnforcer, leader proto.AccessControlClient, version uint64) error {
+	events, err := leader.WatchPolicy(ctx, &proto.WatchPolicyReq{FromPolicyVersion: version})
+	if err != nil {
+		return err
+	}
+	for {
+		pev, err := events.Recv()
+		if err == io.EOF {
+			return errors.New("leader closed the policy stream")
+		}
+		if err != nil {
+			return err
+		}
+		if pev.GetType() == proto.PolicyEvent_RELOAD {
+			return errLeaderReloaded
+		}
+		ev := policyEvent{
+			version: pev.GetPolicyVersion(),
+			typ:     eventAdd,
+			sec:     pev.GetSec(),
+			rule:    pev.GetPolicy().GetParams(),
+			time:    time.Unix(pev.GetTimestamp(), 0),
+		}
+		if pev.GetType() == proto.PolicyEvent_REMOVE {
+			ev.typ = eventRemove
+		}
+		if err := pe.replicate(ev); err != nil {
+			return err
+		}
+	}
+}
+
+func fromPolicies(policies []*proto.Policy) [][]string {
+	rules := make([][]string, len(policies))
+	for i, p := range policies {
+		rules[i] = p.GetParams()
+	}
+	return rules
+}
+
+// snapshotAdapter loads a fixed set of rules. Followers use it in place of
+// a policy store; their changes come from the leader and are not saved.
+type snapshotAdapter struct {
+	p, g [][]string
+}
+
+func (a *snapshotAdapter) LoadPolicy(m model.Model) error {
+	for sec, rules := range map[string][][]string{"p": a.p, "g": a.g} {
+		if len(rules) == 0 {
+			continue
+		}
+		ast, ok := m[sec][sec]
+		if !ok {
+			return fmt.Errorf("the model has no %q definition for the replicated rules", sec)
+		}
+		for _, rule := range rules {
+			ast.Policy = append(ast.Policy, append([]string(nil), rule...))
+		}
+	}
+	return nil
+}
+
+func (a *snapshotAdapter) SavePolicy(m model.Model) error {
+	return errors.New("not implemented")
+}
+
+func (a *snapshotAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return errors.New("not implemented")
+}
+
+func (a *snapshotAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return errors.New("not implemented")
+}
+
+func (a *snapshotAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return errors.New("not implemented")
+}
+
+// GetPolicySnapshot returns the complete policy and its version, the
+// starting point for WatchPolicy.
+func (s *server) GetPolicySnapshot(ctx context.Context, req *proto.SnapshotReq) (*proto.PolicySnapshot, error) {
+	p, g, version := s.enforcer.snapshot()
+	return &proto.PolicySnapshot{Policies: toPolicies(p), GroupingPolicies: toPolicies(g), PolicyVersion: version}, nil
+}
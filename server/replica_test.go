@@ -0,0 +1,140 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"context"
+	fileadapter "github.com/casbin/casbin/persist/file-adapter"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const replicaModel = "rbac_with_deny_model.conf"
+
+// startLeader serves the policy file at addr. The returned func stops the
+// server as a crash would, without draining.
+func startLeader(t *testing.T, addr, policyPath string) (*server, func()) {
+	pe, err := newPolicyEnforcer(replicaModel, policyPath, fileadapter.NewAdapter(policyPath), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &server{enforcer: pe, stopping: make(chan struct{})}
+	s := grpc.NewServer()
+	proto.RegisterAccessControlServer(s, srv)
+	go s.Serve(lis)
+	return srv, func() {
+		close(srv.stopping)
+		s.Stop()
+	}
+}
+
+// converged waits until the follower serves the leader's policy at the
+// leader's version.
+func converged(t *testing.T, step string, leader, follower *policyEnforcer) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		lp, lg, lv := leader.snapshot()
+		fp, fg, fv := follower.snapshot()
+		if lv == fv && reflect.DeepEqual(lp, fp) && reflect.DeepEqual(lg, fg) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: follower has p %v, g %v at version %d; leader has p %v, g %v at version %d", step, fp, fg, fv, lp, lg, lv)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplication(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replica")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	policyPath := filepath.Join(dir, "policy.csv")
+	b, err := ioutil.ReadFile("rbac_with_deny_policy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(policyPath, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// reserve a loopback port the restarted leader can listen on again
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	leader, stopLeader := startLeader(t, addr, policyPath)
+	defer func() { stopLeader() }()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	pe, err := newPolicyEnforcer(replicaModel, "", &snapshotAdapter{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	follower := &server{enforcer: pe, leader: addr, stopping: make(chan struct{})}
+	synced, stop := make(chan struct{}), make(chan struct{})
+	defer close(stop)
+	go follow(pe, proto.NewAccessControlClient(conn), synced, stop)
+	select {
+	case <-synced:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the follower did not load the leader's policy")
+	}
+	converged(t, "snapshot", leader.enforcer, pe)
+
+	ctx := context.Background()
+	if _, err := leader.AddPolicy(ctx, &proto.Policy{Params: []string{"carol", "data2", "read", "allow"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := leader.RemoveGroupingPolicy(ctx, &proto.Policy{Params: []string{"alice", "admin"}}); err != nil {
+		t.Fatal(err)
+	}
+	converged(t, "edits", leader.enforcer, pe)
+	if ok, _ := pe.Enforce(request{sub: "carol", obj: "data2", act: "read"}); !ok {
+		t.Error("the follower does not apply the replicated rule")
+	}
+
+	_, err = follower.AddPolicy(ctx, &proto.Policy{Params: []string{"mallory", "data1", "write", "allow"}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("a write on the follower got %v, want FailedPrecondition", err)
+	}
+	if ok, _ := pe.Enforce(request{sub: "mallory", obj: "data1", act: "write"}); ok {
+		t.Error("the write rejected on the follower changed its policy")
+	}
+
+	// the restarted leader starts again at version 1 with a policy the
+	// follower has never seen
+	stopLeader()
+	if err := ioutil.WriteFile(policyPath, []byte("p, dave, data3, read, allow\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	leader, stopLeader = startLeader(t, addr, policyPath)
+	converged(t, "leader restart", leader.enforcer, pe)
+	if generation, _ := pe.stamp(); generation == 0 {
+		t.Error("the follower kept its generation after the leader restarted")
+	}
+	if _, err := leader.AddPolicy(ctx, &proto.Policy{Params: []string{"erin", "data3", "write", "allow"}}); err != nil {
+		t.Fatal(err)
+	}
+	converged(t, "edit after restart", leader.enforcer, pe)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"context"
+	"fmt"
+	"github.com/casbin/casbin"
+	casbinerrors "github.com/casbin/casbin/errors"
+)
+
+// roleDomain validates dom against the model: a domain model needs one,
+// a model without domains must not get one.
+func roleDomain(e *casbin.Enforcer, dom string) ([]string, error) {
+	if _, ok := e.GetModel()["g"]["g"]; !ok {
+		return nil, &errInvalidArgument{"the model has no role definition"}
+	}
+	_, err := domainIndex(e.GetModel(), "g")
+	switch {
+	case err == nil && dom == "":
+		return nil, &errInvalidArgument{"the model requires a domain"}
+	case err != nil && dom != "":
+		return nil, err
+	case dom != "":
+		return []string{dom}, nil
+	}
+	return nil, nil
+}
+
+// rolesFor returns the roles directly assigned to user.
+func (pe *policyEnforcer) rolesFor(user, dom string) ([]string, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	domain, err := roleDomain(pe.e, dom)
+	if err != nil {
+		return nil, err
+	}
+	return pe.e.GetModel()["g"]["g"].RM.GetRoles(user, domain...)
+}
+
+// usersFor returns the users and roles directly assigned role.
+func (pe *policyEnforcer) usersFor(role, dom string) ([]string, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	domain, err := roleDomain(pe.e, dom)
+	if err != nil {
+		return nil, err
+	}
+	users, err := pe.e.GetModel()["g"]["g"].RM.GetUsers(role, domain...)
+	if err == casbinerrors.ERR_NAME_NOT_FOUND {
+		// the role manager reports unknown roles as an error
+		return nil, nil
+	}
+	return users, err
+}
+
+// implicitRolesFor returns every role user inherits, directly or through
+// other roles.
+func (pe *policyEnforcer) implicitRolesFor(user, dom string) ([]string, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	return implicitRoles(pe.e, user, dom)
+}
+
+func implicitRoles(e *casbin.Enforcer, user, dom string) (roles []string, err error) {
+	domain, err := roleDomain(e, dom)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			roles, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	return e.GetImplicitRolesForUser(user, domain...), nil
+}
+
+// implicitPermissionsFor returns the policy rules granted to user itself or
+// to any role it inherits.
+func (pe *policyEnforcer) implicitPermissionsFor(user, dom string) ([][]string, error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	roles, err := implicitRoles(pe.e, user, dom)
+	if err != nil {
+		return nil, err
+	}
+	di := -1
+	if dom != "" {
+		if di, err = domainIndex(pe.e.GetModel(), "p"); err != nil {
+			return nil, err
+		}
+	}
+	var perms [][]string
+	for _, name := range append([]string{user}, roles...) {
+		for _, rule := range pe.e.GetFilteredPolicy(0, name) {
+			if di < 0 || rule[di] == dom {
+				perms = append(perms, rule)
+			}
+		}
+	}
+	return perms, nil
+}
+
+func (s *server) GetRolesForUser(ctx context.Context, req *proto.RoleReq) (*proto.RoleList, error) {
+	roles, err := s.enforcer.rolesFor(req.GetUser(), req.GetDom())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &proto.RoleList{Names: roles}, nil
+}
+
+func (s *server) GetUsersForRole(ctx context.Context, req *proto.RoleReq) (*proto.RoleList, error) {
+	users, err := s.enforcer.usersFor(req.GetRole(), req.GetDom())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &proto.RoleList{Names: users}, nil
+}
+
+func (s *server) GetImplicitRolesForUser(ctx context.Context, req *proto.RoleReq) (*proto.RoleList, error) {
+	roles, err := s.enforcer.implicitRolesFor(req.GetUser(), req.GetDom())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &proto.RoleList{Names: roles}, nil
+}
+
+func (s *server) GetImplicitPermissionsForUser(ctx context.Context, req *proto.RoleReq) (*proto.PolicyList, error) {
+	perms, err := s.enforcer.implicitPermissionsFor(req.GetUser(), req.GetDom())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &proto.PolicyList{Policies: toPolicies(perms)}, nil
+}
+
+// HasRoleForUser reports whether the role is directly assigned to the user;
+// use GetImplicitRolesForUser to include inherited roles.
+func (s *server) HasRoleForUser(ctx context.Context, req *proto.RoleReq) (*proto.HasRoleResp, error) {
+	roles, err := s.enforcer.rolesFor(req.GetUser(), req.GetDom())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	for _, role := range roles {
+		if role == req.GetRole() {
+			return &proto.HasRoleResp{Res: true}, nil
+		}
+	}
+	return &proto.HasRoleResp{Res: false}, nil
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/casbin/casbin/model"
+	"github.com/casbin/casbin/rbac"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Grants and role memberships can be limited in time by a validity field
+// holding a schedule:
+//
+//	*                                  always
+//	2026-01-01/2026-03-31              from the first to the end of the last day, UTC
+//	2026-01-05T09:00:00+01:00/         from then on
+//	/2026-06-30T18:00:00Z              until then
+//	Mon-Fri 09:00-18:00 Europe/Berlin  weekdays during office hours in Berlin
+//	Sat+Sun                            all day on weekends, UTC
+//	22:00-06:00                        nightly, across midnight
+//
+// Clauses separated by ";" must all hold, e.g. a contract period and office
+// hours. A "p" rule has the field if the policy definition names it "when",
+// e.g. "p = sub, obj, act, when"; a "g" rule if the role definition has a
+// field after the names and domain, e.g. "g = _, _, _" in a model without
+// domains. The matcher checks it with
+//
+//	active(p.when, r.env_attrs)                the grant is active at the request time
+//	gActive(r.sub, p.sub, r.env_attrs)         r.sub has role p.sub at the request time
+//	gActive(r.sub, p.sub, r.dom, r.env_attrs)  the same within a domain
+//
+// as in server/rbac_with_time_model.conf. The request time is the "time"
+// environment attribute, which the server sets to its clock; a time sent by
+// the caller is used only with -trust-request-time, and in -test suites.
+// Without the env_attrs argument the server clock is used.
+// Role queries and explanations list memberships regardless of time. With
+// -prune-interval, rules whose schedule has ended are removed from the store;
+// they are kept by default, as they never match again anyway.
+
+// period is one clause of a schedule: absolute bounds, a weekly recurrence
+// or both.
+type period struct {
+	// from and until bound the clause, zero if open; until is exclusive
+	from, until time.Time
+
+	// days are the weekdays of the recurrence, all if none is set
+	days [7]bool
+	// start and end are minutes of the day; start == end is all day
+	start, end int
+	loc        *time.Location
+	recurring  bool
+}
+
+// schedule holds if all its periods do; an empty schedule always holds.
+type schedule []period
+
+// zones caches the time zones loaded so far, which unlike schedules are
+// bounded by the zone database.
+var zones sync.Map
+
+// parseSchedule parses a validity field, see above.
+func parseSchedule(spec string) (schedule, error) {
+	var s schedule
+	for _, clause := range strings.Split(spec, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" || clause == "*" {
+			continue
+		}
+		var (
+			p   period
+			err error
+		)
+		if strings.Contains(clause, "/") && !strings.Contains(clause, " ") {
+			p, err = parseBounds(clause)
+		} else {
+			p, err = parseRecurrence(clause)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %v", spec, err)
+		}
+		s = append(s, p)
+	}
+	return s, nil
+}
+
+// parseBounds parses "from/until", either of which may be empty.
+func parseBounds(clause string) (period, error) {
+	bounds := strings.SplitN(clause, "/", 2)
+	var p period
+	var err error
+	if bounds[0] != "" {
+		if p.from, err = parseBound(bounds[0], false); err != nil {
+			return period{}, err
+		}
+	}
+	if bounds[1] != "" {
+		if p.until, err = parseBound(bounds[1], true); err != nil {
+			return period{}, err
+		}
+	}
+	if !p.from.IsZero() && !p.until.IsZero() && !p.from.Before(p.until) {
+		return period{}, fmt.Errorf("%s ends before it starts", clause)
+	}
+	return p, nil
+}
+
+// parseBound parses an RFC 3339 time or a date, which stands for the whole
+// day in UTC: its start as a lower bound and its end as an upper one.
+func parseBound(s string, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is neither a date nor an RFC 3339 time", s)
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseRecurrence parses "[days] [hh:mm-hh:mm] [zone]" with at least days
+// or hours, e.g. "Mon-Fri 09:00-18:00 Europe/Berlin".
+func parseRecurrence(clause string) (period, error) {
+	p := period{recurring: true, loc: time.UTC}
+	fields := strings.Fields(clause)
+	var hasDays, hasHours bool
+	for i, field := range fields {
+		switch {
+		case !hasDays && !hasHours && isDays(field):
+			for _, part := range strings.Split(field, "+") {
+				days := strings.SplitN(part, "-", 2)
+				first, last := weekdays[strings.ToLower(days[0])], weekdays[strings.ToLower(days[len(days)-1])]
+				for d := first; ; d = (d + 1) % 7 {
+					p.days[d] = true
+					if d == last {
+						break
+					}
+				}
+			}
+			hasDays = true
+		case !hasHours && strings.Contains(field, ":"):
+			hours := strings.SplitN(field, "-", 2)
+			if len(hours) != 2 {
+				return period{}, fmt.Errorf("%s is not a range of hours like 09:00-18:00", field)
+			}
+			var err error
+			if p.start, err = parseClock(hours[0]); err != nil {
+				return period{}, err
+			}
+			if p.end, err = parseClock(hours[1]); err != nil {
+				return period{}, err
+			}
+			p.end %= 24 * 60
+			hasHours = true
+		case i == len(fields)-1 && (hasDays || hasHours):
+			loc, err := loadZone(field)
+			if err != nil {
+				return period{}, err
+			}
+			p.loc = loc
+		default:
+			return period{}, fmt.Errorf("unexpected %q, want days like Mon-Fri, hours like 09:00-18:00 and a time zone", field)
+		}
+	}
+	if !hasDays {
+		for d := range p.days {
+			p.days[d] = true
+		}
+	}
+	return p, nil
+}
+
+// loadZone returns the named time zone; time.LoadLocation reads it from disk.
+func loadZone(name string) (*time.Location, error) {
+	if loc, ok := zones.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %s", name)
+	}
+	zones.Store(name, loc)
+	return loc, nil
+}
+
+// isDays reports whether field is a list of weekdays like "Mon-Wed+Fri".
+func isDays(field string) bool {
+	for _, part := range strings.Split(field, "+") {
+		for _, day := range strings.SplitN(part, "-", 2) {
+			if _, ok := weekdays[strings.ToLower(day)]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseClock returns the minute of the day of "hh:mm"; "24:00" is allowed
+// as the end of the day.
+func parseClock(s string) (int, error) {
+	hm := strings.SplitN(s, ":", 2)
+	if len(hm) == 2 {
+		h, herr := strconv.Atoi(hm[0])
+		m, merr := strconv.Atoi(hm[1])
+		if herr == nil && merr == nil && h >= 0 && m >= 0 && m < 60 && (h < 24 || h == 24 && m == 0) {
+			return h*60 + m, nil
+		}
+	}
+	return 0, fmt.Errorf("%s is not a time of day like 09:00", s)
+}
+
+// activeAt reports whether the schedule holds at t.
+func (s schedule) activeAt(t time.Time) bool {
+	for _, p := range s {
+		if !p.from.IsZero() && t.Before(p.from) || !p.until.IsZero() && !t.Before(p.until) {
+			return false
+		}
+		if !p.recurring {
+			continue
+		}
+		local := t.In(p.loc)
+		if !p.days[local.Weekday()] {
+			return false
+		}
+		minute := local.Hour()*60 + local.Minute()
+		switch {
+		case p.start < p.end && (minute < p.start || minute >= p.end):
+			return false
+		case p.start > p.end && minute < p.start && minute >= p.end:
+			return false
+		}
+	}
+	return true
+}
+
+// endedBy reports whether the schedule will never hold again after t.
+func (s schedule) endedBy(t time.Time) bool {
+	for _, p := range s {
+		if !p.until.IsZero() && !t.Before(p.until) {
+			return true
+		}
+	}
+	return false
+}
+
+// whenIndex returns the position of the validity field in rules of section
+// "p" (the p.when token) or "g" (the field after the names and the domain).
+func whenIndex(m model.Model, sec string) (int, bool) {
+	ast, ok := m[sec][sec]
+	if !ok {
+		return 0, false
+	}
+	if sec == "g" {
+		names := 2
+		if _, err := domainIndex(m, "g"); err == nil {
+			names = 3
+		}
+		return names, strings.Count(ast.Value, "_") > names
+	}
+	for i, token := range ast.Tokens {
+		if token == "p_when" {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// timedModel reports whether rules of the model have a validity field.
+func timedModel(m model.Model) bool {
+	_, p := whenIndex(m, "p")
+	_, g := whenIndex(m, "g")
+	return p || g
+}
+
+// activeFunc implements active(when[, env_attrs]). A rule with an invalid
+// schedule is never active.
+func activeFunc(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errors.New("active takes a schedule and optionally r.env_attrs")
+	}
+	spec, ok := args[0].(string)
+	if !ok {
+		return nil, errors.New("the schedule must be a string")
+	}
+	t, err := requestTime(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseSchedule(spec)
+	return err == nil && s.activeAt(t), nil
+}
+
+// groupActiveFunc implements gActive(user, role[, dom][, env_attrs]) over
+// the "g" rules of m, which may change between calls.
+func groupActiveFunc(m model.Model) func(args ...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		t := time.Now()
+		if n := len(args); n > 0 {
+			if _, ok := args[n-1].(attributes); ok {
+				var err error
+				if t, err = requestTime(args[n-1:]); err != nil {
+					return nil, err
+				}
+				args = args[:n-1]
+			}
+		}
+		if len(args) != 2 && len(args) != 3 {
+			return nil, errors.New("gActive takes a user, a role, optionally a domain and optionally r.env_attrs")
+		}
+		names := make([]string, len(args))
+		for i, arg := range args {
+			s, ok := arg.(string)
+			if !ok {
+				return nil, errors.New("gActive takes names as strings")
+			}
+			names[i] = s
+		}
+		return hasActiveRole(m, t, names[0], names[1], names[2:]...), nil
+	}
+}
+
+// hasActiveRole reports whether user inherits role through memberships
+// active at t, within the domain if one is given.
+func hasActiveRole(m model.Model, t time.Time, user, role string, domain ...string) bool {
+	if user == role {
+		return true
+	}
+	ast, ok := m["g"]["g"]
+	if !ok {
+		return false
+	}
+	di, err := domainIndex(m, "g")
+	if err != nil && len(domain) > 0 {
+		return false
+	}
+	wi, timed := whenIndex(m, "g")
+	seen := map[string]bool{user: true}
+	queue := []string{user}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, rule := range ast.Policy {
+			if len(rule) < 2 || rule[0] != name || seen[rule[1]] {
+				continue
+			}
+			if len(domain) > 0 && (di >= len(rule) || rule[di] != domain[0]) {
+				continue
+			}
+			if timed && wi < len(rule) {
+				if s, err := parseSchedule(rule[wi]); err != nil || !s.activeAt(t) {
+					continue
+				}
+			}
+			if rule[1] == role {
+				return true
+			}
+			seen[rule[1]] = true
+			queue = append(queue, rule[1])
+		}
+	}
+	return false
+}
+
+// requestTime returns the time in the "time" attribute of the optional
+// env_attrs argument, or the server clock.
+func requestTime(args []interface{}) (time.Time, error) {
+	if len(args) == 0 {
+		return time.Now(), nil
+	}
+	attrs, ok := args[0].(attributes)
+	if !ok {
+		return time.Time{}, errors.New("the time argument must be r.env_attrs")
+	}
+	switch v := attrs["time"].(type) {
+	case nil:
+		return time.Now(), nil
+	case float64:
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)), nil
+	}
+	return time.Time{}, errors.New("the time attribute must be in Unix seconds")
+}
+
+// timedRoleManager is casbin's role manager for role definitions with a
+// validity field, which it leaves out of the role links; the links then
+// hold regardless of time.
+type timedRoleManager struct {
+	rbac.RoleManager
+}
+
+func (rm timedRoleManager) AddLink(name1 string, name2 string, domain ...string) error {
+	if len(domain) == 0 {
+		return rm.RoleManager.AddLink(name1, name2)
+	}
+	return rm.RoleManager.AddLink(name1, name2, domain[:len(domain)-1]...)
+}
+
+func (rm timedRoleManager) DeleteLink(name1 string, name2 string, domain ...string) error {
+	if len(domain) == 0 {
+		return rm.RoleManager.DeleteLink(name1, name2)
+	}
+	return rm.RoleManager.DeleteLink(name1, name2, domain[:len(domain)-1]...)
+}
+
+// prune removes rules whose schedule has ended every interval until stop is
+// closed. Removals are recorded and published like any other edit.
+func (pe *policyEnforcer) prune(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, c := range pe.expired(time.Now()) {
+				if _, err := pe.removePolicy(c.Sec, "", c.Rule, "prune"); err != nil {
+					log.Println("failed to prune expired rule:", c.Sec, c.Rule, err)
+					continue
+				}
+				log.Println("pruned expired rule:", c.Sec, c.Rule)
+			}
+		}
+	}
+}
+
+// expired returns the rules whose schedule has ended by t.
+func (pe *policyEnforcer) expired(t time.Time) []ruleChange {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	m := pe.e.GetModel()
+	var rules []ruleChange
+	for _, sec := range []string{"p", "g"} {
+		i, ok := whenIndex(m, sec)
+		if !ok {
+			continue
+		}
+		for _, rule := range m[sec][sec].Policy {
+			if i >= len(rule) {
+				continue
+			}
+			if s, err := parseSchedule(rule[i]); err == nil && s.endedBy(t) {
+				rules = append(rules, ruleChange{Sec: sec, Rule: append([]string(nil), rule...)})
+			}
+		}
+	}
+	return rules
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleActiveAt(t *testing.T) {
+	tests := []struct {
+		spec string
+		at   string
+		want bool
+	}{
+		{"*", "2026-01-05T09:00:00Z", true},
+		{"", "2026-01-05T09:00:00Z", true},
+
+		// a date-only end covers that whole day, the end is exclusive
+		{"2026-01-01/2026-03-31", "2025-12-31T23:59:59Z", false},
+		{"2026-01-01/2026-03-31", "2026-01-01T00:00:00Z", true},
+		{"2026-01-01/2026-03-31", "2026-03-31T23:59:59Z", true},
+		{"2026-01-01/2026-03-31", "2026-04-01T00:00:00Z", false},
+		{"2026-01-05T09:00:00+01:00/", "2026-01-05T07:59:59Z", false},
+		{"2026-01-05T09:00:00+01:00/", "2030-01-01T00:00:00Z", true},
+		{"/2026-06-30T18:00:00Z", "2026-06-30T17:59:59Z", true},
+		{"/2026-06-30T18:00:00Z", "2026-06-30T18:00:00Z", false},
+
+		// 2026-01-05 is a Monday
+		{"Mon-Fri", "2026-01-09T23:59:00Z", true},
+		{"Mon-Fri", "2026-01-10T00:00:00Z", false},
+		{"Fri-Mon", "2026-01-10T12:00:00Z", true},
+		{"Fri-Mon", "2026-01-12T12:00:00Z", true},
+		{"Fri-Mon", "2026-01-13T12:00:00Z", false},
+		{"Sat+Sun", "2026-01-11T12:00:00Z", true},
+		{"Sat+Sun", "2026-01-09T12:00:00Z", false},
+		{"mon-wed+fri", "2026-01-08T12:00:00Z", false},
+		{"mon-wed+fri", "2026-01-09T12:00:00Z", true},
+
+		{"09:00-18:00", "2026-01-05T08:59:00Z", false},
+		{"09:00-18:00", "2026-01-05T09:00:00Z", true},
+		{"09:00-18:00", "2026-01-05T18:00:00Z", false},
+		{"22:00-06:00", "2026-01-05T21:59:00Z", false},
+		{"22:00-06:00", "2026-01-05T23:30:00Z", true},
+		{"22:00-06:00", "2026-01-06T05:59:00Z", true},
+		{"22:00-06:00", "2026-01-06T06:00:00Z", false},
+		{"18:00-24:00", "2026-01-05T23:59:00Z", true},
+		{"18:00-24:00", "2026-01-05T00:00:00Z", false},
+		{"00:00-24:00", "2026-01-05T03:00:00Z", true},
+
+		// Berlin is UTC+1 in winter and UTC+2 in summer
+		{"Mon-Fri 09:00-18:00 Europe/Berlin", "2026-01-05T08:00:00Z", true},
+		{"Mon-Fri 09:00-18:00 Europe/Berlin", "2026-01-05T17:00:00Z", false},
+		{"Mon-Fri 09:00-18:00 Europe/Berlin", "2026-07-06T07:00:00Z", true},
+		{"Mon-Fri 09:00-18:00 Europe/Berlin", "2026-07-06T16:00:00Z", false},
+		// Friday 23:30 UTC is already Saturday in Tokyo
+		{"Mon-Fri Asia/Tokyo", "2026-01-09T23:30:00Z", false},
+
+		{"2026-01-01/2026-06-30; Mon-Fri", "2026-01-05T12:00:00Z", true},
+		{"2026-01-01/2026-06-30; Mon-Fri", "2026-01-10T12:00:00Z", false},
+		{"2026-01-01/2026-06-30; Mon-Fri", "2026-07-06T12:00:00Z", false},
+	}
+	for _, test := range tests {
+		s, err := parseSchedule(test.spec)
+		if err != nil {
+			t.Errorf("%q: %v", test.spec, err)
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, test.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.activeAt(at); got != test.want {
+			t.Errorf("%q at %s: got %v, want %v", test.spec, test.at, got, test.want)
+		}
+	}
+}
+
+func TestScheduleEndedBy(t *testing.T) {
+	at := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{"*", false},
+		{"Mon-Fri 09:00-18:00", false},
+		{"2026-01-01/", false},
+		{"2026-01-01/2026-03-31", true},
+		{"2026-01-01/2026-04-01", false},
+		{"/2026-03-31T23:59:59Z", true},
+		{"Mon-Fri; 2026-01-01/2026-03-31", true},
+	}
+	for _, test := range tests {
+		s, err := parseSchedule(test.spec)
+		if err != nil {
+			t.Errorf("%q: %v", test.spec, err)
+			continue
+		}
+		if got := s.endedBy(at); got != test.want {
+			t.Errorf("%q: got %v, want %v", test.spec, got, test.want)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"2026-02-01/2026-01-01",
+		"2026-13-01/",
+		"tomorrow/",
+		"Mon-Xyz",
+		"09:00",
+		"25:00-26:00",
+		"09:60-10:00",
+		"24:30-01:00",
+		"Mon-Fri 09:00-18:00 Mars/Base",
+		"Europe/Berlin Mon-Fri",
+		"Mon-Fri Mon",
+		"09:00-10:00 10:00-11:00",
+		"*; Sat+Funday",
+	} {
+		if _, err := parseSchedule(spec); err == nil {
+			t.Errorf("%q: parsed, want an error", spec)
+		}
+	}
+}
+
+func TestRequestTimeNeedsTrust(t *testing.T) {
+	sent := fromAttributes(attributes{"time": float64(1767603600)})
+	if got := envAttributes(sent, false)["time"]; got == float64(1767603600) {
+		t.Error("the time sent by the caller replaced the server clock")
+	}
+	if got := envAttributes(sent, true)["time"]; got != float64(1767603600) {
+		t.Errorf("got time %v, want the time sent by the caller", got)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"context"
+	"fmt"
+	"github.com/casbin/casbin/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"sort"
+)
+
+// SimulatePolicyChange tells whose access a proposed change would affect
+// without touching the live policy. The change is applied to a sandbox
+// loaded from a snapshot of the live policy, removals first, and every
+// request built from the subjects, domains, objects and actions named in
+// either policy is decided by both. Requests whose decision flips are
+// reported. Models with attributes are not enumerated, since their decisions
+// depend on the attributes sent; replaying recent requests from the audit
+// log covers them, as well as objects only matched by patterns.
+
+// simChange is a proposed rule change. A non-empty dom is inserted into
+// params as for addPolicy.
+type simChange struct {
+	sec, dom string
+	params   []string
+	add      bool
+}
+
+// flip is a request the change decides differently.
+type flip struct {
+	r             request
+	before, after bool
+	replayed      bool
+}
+
+type simulation struct {
+	flips []flip
+	// version is the policy version the sandbox was copied from
+	version   uint64
+	evaluated int
+	replayed  int
+	truncated bool
+}
+
+// simulate applies changes to a sandbox copy of the live policy and decides
+// the replayed requests, then the enumerated ones, at most limit in all.
+func (pe *policyEnforcer) simulate(changes []simChange, replay []request, limit int) (*simulation, error) {
+	p, g, version := pe.snapshot()
+	before, err := loadEnforcer(pe.modelPath, &snapshotAdapter{p: p, g: g}, pe.effect)
+	if err != nil {
+		return nil, err
+	}
+	after, err := loadEnforcer(pe.modelPath, &snapshotAdapter{p: p, g: g}, pe.effect)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range changes {
+		rule, err := withDomain(after.GetModel(), c.sec, c.dom, c.params)
+		if err == nil {
+			err = checkRule(after, c.sec, rule)
+		}
+		if err != nil {
+			return nil, &errInvalidArgument{fmt.Sprintf("change %d: %v", i, err)}
+		}
+		ok, err := applyRule(after, c.sec, rule, c.add)
+		if err != nil {
+			return nil, err
+		}
+		if !ok && c.add {
+			return nil, &errInvalidArgument{fmt.Sprintf("change %d: %s rule %v is already in the policy", i, c.sec, rule)}
+		}
+		if !ok {
+			return nil, &errInvalidArgument{fmt.Sprintf("change %d: %s rule %v is not in the policy", i, c.sec, rule)}
+		}
+	}
+
+	sim := &simulation{version: version}
+	seen := make(map[string]bool)
+	var reqs []request
+	attrs := attributeModel(before.GetModel())
+	for _, r := range replay {
+		if _, err := requestValues(before.GetModel(), r); err != nil {
+			// logged for an earlier model
+			continue
+		}
+		if !attrs {
+			// e.g. the request time, which the model does not look at
+			r.subAttrs, r.objAttrs, r.envAttrs = nil, nil, nil
+		}
+		if len(r.subAttrs)+len(r.objAttrs)+len(r.envAttrs) == 0 {
+			if seen[cacheKey(r)] {
+				continue
+			}
+			seen[cacheKey(r)] = true
+		}
+		reqs = append(reqs, r)
+	}
+	if len(reqs) > limit {
+		reqs, sim.truncated = reqs[:limit], true
+	}
+	sim.replayed = len(reqs)
+	if !attrs && !sim.truncated {
+		more, truncated := candidates(limit-len(reqs), seen, before.GetModel(), after.GetModel())
+		reqs, sim.truncated = append(reqs, more...), truncated
+	}
+
+	for i, r := range reqs {
+		b, err := enforce(before, r)
+		if err != nil {
+			return nil, fmt.Errorf("request %s %s %s %s: %v", r.sub, r.dom, r.obj, r.act, err)
+		}
+		a, err := enforce(after, r)
+		if err != nil {
+			return nil, fmt.Errorf("request %s %s %s %s: %v", r.sub, r.dom, r.obj, r.act, err)
+		}
+		if a != b {
+			sim.flips = append(sim.flips, flip{r: r, before: b, after: a, replayed: i < sim.replayed})
+		}
+	}
+	sim.evaluated = len(reqs)
+	return sim, nil
+}
+
+// candidates enumerates the requests built from the values named in the
+// rules of the models, skipping and adding to seen, up to limit. It reports
+// whether it stopped at the limit.
+func candidates(limit int, seen map[string]bool, models ...model.Model) ([]request, bool) {
+	subs, doms, objs, acts := map[string]bool{}, map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, m := range models {
+		if ast, ok := m["p"]["p"]; ok {
+			for _, rule := range ast.Policy {
+				for i, token := range ast.Tokens {
+					if i >= len(rule) {
+						break
+					}
+					switch token {
+					case "p_sub":
+						subs[rule[i]] = true
+					case "p_dom":
+						doms[rule[i]] = true
+					case "p_obj":
+						objs[rule[i]] = true
+					case "p_act":
+						acts[rule[i]] = true
+					}
+				}
+			}
+		}
+		if ast, ok := m["g"]["g"]; ok {
+			di, err := domainIndex(m, "g")
+			for _, rule := range ast.Policy {
+				for i, v := range rule {
+					if i < 2 {
+						subs[v] = true
+					} else if err == nil && i == di {
+						doms[v] = true
+					}
+				}
+			}
+		}
+	}
+	domList := []string{""}
+	if hasToken(models[0], "r", "r_dom") {
+		domList = sortedKeys(doms)
+	}
+
+	var reqs []request
+	for _, sub := range sortedKeys(subs) {
+		for _, dom := range domList {
+			for _, obj := range sortedKeys(objs) {
+				for _, act := range sortedKeys(acts) {
+					r := request{sub: sub, dom: dom, obj: obj, act: act}
+					if seen[cacheKey(r)] {
+						continue
+					}
+					if len(reqs) >= limit {
+						return reqs, true
+					}
+					seen[cacheKey(r)] = true
+					reqs = append(reqs, r)
+				}
+			}
+		}
+	}
+	return reqs, false
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SimulatePolicyChange reports the decisions the proposed additions and
+// removals would flip. With replay, the latest decisions of the audit log
+// are decided again as well.
+func (s *server) SimulatePolicyChange(ctx context.Context, req *proto.SimulateReq) (*proto.SimulateResp, error) {
+	var changes []simChange
+	for _, c := range req.GetRemove() {
+		changes = append(changes, simChange{sec: c.GetSec(), dom: c.GetPolicy().GetDom(), params: c.GetPolicy().GetParams()})
+	}
+	for _, c := range req.GetAdd() {
+		changes = append(changes, simChange{sec: c.GetSec(), dom: c.GetPolicy().GetDom(), params: c.GetPolicy().GetParams(), add: true})
+	}
+	if len(changes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no changes to simulate")
+	}
+	var replay []request
+	if req.GetReplay() > SIMULATE_LIMIT {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot replay more than %d decisions", SIMULATE_LIMIT)
+	}
+	if req.GetReplay() > 0 {
+		if s.audit == nil {
+			return nil, status.Error(codes.FailedPrecondition, "replaying decisions needs a server with -audit-log")
+		}
+		entries, err := s.audit.Recent(int(req.GetReplay()))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "audit: %v", err)
+		}
+		for _, e := range entries {
+			replay = append(replay, request{
+				sub:      e.Request.Sub,
+				dom:      e.Request.Dom,
+				obj:      e.Request.Obj,
+				act:      e.Request.Act,
+				subAttrs: attributes(e.Request.SubAttrs),
+				objAttrs: attributes(e.Request.ObjAttrs),
+				envAttrs: attributes(e.Request.EnvAttrs),
+			})
+		}
+	}
+
+	sim, err := s.enforcer.simulate(changes, replay, SIMULATE_LIMIT)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	log.Println("simulated", len(changes), "rule changes:", sim.evaluated, "requests,", len(sim.flips), "flipped")
+	resp := &proto.SimulateResp{
+		PolicyVersion: sim.version,
+		Evaluated:     uint32(sim.evaluated),
+		Replayed:      uint32(sim.replayed),
+		Truncated:     sim.truncated,
+	}
+	for _, f := range sim.flips {
+		resp.Changes = append(resp.Changes, &proto.DecisionChange{
+			Req: &proto.AccessControlReq{
+				Sub:      f.r.sub,
+				Dom:      f.r.dom,
+				Obj:      f.r.obj,
+				Act:      f.r.act,
+				SubAttrs: fromAttributes(f.r.subAttrs),
+				ObjAttrs: fromAttributes(f.r.objAttrs),
+				EnvAttrs: fromAttributes(f.r.envAttrs),
+			},
+			Before:   f.before,
+			After:    f.after,
+			Replayed: f.replayed,
+		})
+	}
+	return resp, nil
+}
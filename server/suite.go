@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A policy test suite is a YAML file of requests and their expected
+// decisions, run with -test against the model and policy loaded exactly as
+// the server loads them, e.g. server/rbac_with_deny_test.yaml:
+//
+//	model: rbac_with_deny_model.conf
+//	policy: rbac_with_deny_policy.csv
+//	cases:
+//	  - {sub: bob, obj: data1, act: write, expect: true}
+//	  - name: a deny rule overrides alice's admin role
+//	    sub: alice
+//	    obj: data1
+//	    act: write
+//	    expect: false
+//
+// Relative model and policy paths are resolved against the suite file; they
+// default to -model and -policy. Cases may also set dom, sub_attrs,
+// obj_attrs and env_attrs. The run prints the failed cases and the policy
+// rules no case exercised, that is no "p" rule that matched and no "g" rule
+// on the role path to one, and exits non-zero if a case failed.
+
+type policySuite struct {
+	Model  string      `yaml:"model"`
+	Policy string      `yaml:"policy"`
+	Cases  []suiteCase `yaml:"cases"`
+}
+
+type suiteCase struct {
+	Name     string                 `yaml:"name"`
+	Sub      string                 `yaml:"sub"`
+	Dom      string                 `yaml:"dom"`
+	Obj      string                 `yaml:"obj"`
+	Act      string                 `yaml:"act"`
+	SubAttrs map[string]interface{} `yaml:"sub_attrs"`
+	ObjAttrs map[string]interface{} `yaml:"obj_attrs"`
+	EnvAttrs map[string]interface{} `yaml:"env_attrs"`
+	Expect   *bool                  `yaml:"expect"`
+}
+
+func (c suiteCase) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return strings.Join(nonEmpty(c.Sub, c.Dom, c.Obj, c.Act), ", ")
+}
+
+// runSuite runs the suite at path and returns the exit status: 0 if every
+// case passed, 1 if one failed and 2 if the suite could not be run.
+func runSuite(path, modelPath, policyPath, store, effect string) int {
+	suite, err := readSuite(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if suite.Model != "" {
+		modelPath = suitePath(path, suite.Model)
+	}
+	if suite.Policy != "" {
+		policyPath = suitePath(path, suite.Policy)
+	}
+	adapter, closeAdapter, err := newAdapter(store, policyPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to open policy store:", err)
+		return 2
+	}
+	defer closeAdapter()
+	pe, err := newPolicyEnforcer(modelPath, policyPath, adapter, effect)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load policy:", err)
+		return 2
+	}
+
+	p, g, _ := pe.snapshot()
+	pHit, gHit := make([]bool, len(p)), make([]bool, len(g))
+	gIndex := make(map[string]int, len(g))
+	for i := len(g) - 1; i >= 0; i-- {
+		gIndex[ruleKey(g[i])] = i
+	}
+	failed := 0
+	for i, c := range suite.Cases {
+		r, err := c.request()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: case %d: %v\n", path, i+1, err)
+			return 2
+		}
+		ex, err := pe.Explain(r)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", c, err)
+			failed++
+			continue
+		}
+		for _, m := range ex.rules {
+			pHit[m.index] = true
+			for _, link := range m.rolePath {
+				if j, ok := gIndex[ruleKey(link)]; ok {
+					gHit[j] = true
+				}
+			}
+		}
+		if ex.res != *c.Expect {
+			failed++
+			by := "no rule matched"
+			if rule := ex.decision().rule; rule != nil {
+				by = "decided by p, " + strings.Join(rule, ", ")
+			}
+			fmt.Printf("FAIL %s: got %v, want %v (%s)\n", c, ex.res, *c.Expect, by)
+		}
+	}
+	fmt.Printf("%d cases, %d failed\n", len(suite.Cases), failed)
+
+	lines := map[string][]int{}
+	if store == "csv" {
+		lines = policyLines(policyPath)
+	}
+	covered := 0
+	var missed []string
+	for _, sec := range []string{"p", "g"} {
+		rules, hits := p, pHit
+		if sec == "g" {
+			rules, hits = g, gHit
+		}
+		for i, hit := range hits {
+			if hit {
+				covered++
+				continue
+			}
+			where := policyPath
+			if i < len(lines[sec]) {
+				where = fmt.Sprintf("%s:%d", policyPath, lines[sec][i])
+			}
+			missed = append(missed, fmt.Sprintf("%s: %s, %s", where, sec, strings.Join(rules[i], ", ")))
+		}
+	}
+	if total := len(p) + len(g); total > 0 {
+		fmt.Printf("coverage: %d of %d policy rules exercised (%.0f%%)\n", covered, total, 100*float64(covered)/float64(total))
+	}
+	if len(missed) > 0 {
+		fmt.Println("not exercised:")
+		for _, m := range missed {
+			fmt.Println("  " + m)
+		}
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func readSuite(path string) (*policySuite, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	suite := &policySuite{}
+	if err := yaml.UnmarshalStrict(b, suite); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("%s: no cases", path)
+	}
+	for i, c := range suite.Cases {
+		if c.Expect == nil {
+			return nil, fmt.Errorf("%s: case %d (%s) has no expect", path, i+1, c)
+		}
+	}
+	return suite, nil
+}
+
+// suitePath resolves a file named in the suite at path.
+func suitePath(path, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(path), name)
+}
+
+// request builds the request the server would make of a Check with the
+// case's fields.
+func (c suiteCase) request() (request, error) {
+	r := request{sub: c.Sub, dom: c.Dom, obj: c.Obj, act: c.Act}
+	if r.sub == "" {
+		return request{}, errors.New("the case has no subject")
+	}
+	var err error
+	if r.subAttrs, err = suiteAttributes(c.SubAttrs); err != nil {
+		return request{}, err
+	}
+	if r.objAttrs, err = suiteAttributes(c.ObjAttrs); err != nil {
+		return request{}, err
+	}
+	env, err := suiteAttributes(c.EnvAttrs)
+	if err != nil {
+		return request{}, err
+	}
+	// through the wire form, so the server's defaults apply; a case may
+	// set the time to test schedules
+	r.envAttrs = envAttributes(fromAttributes(env), true)
+	return r, nil
+}
+
+// suiteAttributes converts YAML values to attribute values.
+func suiteAttributes(m map[string]interface{}) (attributes, error) {
+	attrs := make(attributes, len(m))
+	for name, v := range m {
+		switch v := v.(type) {
+		case string, bool, float64:
+			attrs[name] = v
+		case int:
+			attrs[name] = float64(v)
+		default:
+			return nil, fmt.Errorf("attribute %s must be a string, number or bool", name)
+		}
+	}
+	return attrs, nil
+}
+
+// policyLines returns the line numbers of the "p" and "g" rules of a CSV
+// policy in the order casbin loads them.
+func policyLines(path string) map[string][]int {
+	lines := make(map[string][]int)
+	f, err := os.Open(path)
+	if err != nil {
+		return lines
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if ptype := strings.TrimSpace(strings.Split(text, ",")[0]); ptype == "p" || ptype == "g" {
+			lines[ptype] = append(lines[ptype], line)
+		}
+	}
+	return lines
+}
+
+func nonEmpty(values ...string) []string {
+	var s []string
+	for _, v := range values {
+		if v != "" {
+			s = append(s, v)
+		}
+	}
+	return s
+}
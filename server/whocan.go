@@ -0,0 +1,84 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// whoCan returns the users allowed to perform act on obj, with role
+// inheritance expanded, and the roles whose members are granted it. Every
+// candidate is run through the enforcer, so deny rules and the configured
+// effect are honoured. Users are the subjects that never appear as a role.
+func (pe *policyEnforcer) whoCan(dom, obj, act string) (users, roles []string, err error) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	m := pe.e.GetModel()
+	if _, err := requestValues(m, request{dom: dom, obj: obj, act: act}); err != nil {
+		return nil, nil, err
+	}
+
+	pdi, gdi := -1, -1
+	if dom != "" {
+		if pdi, err = domainIndex(m, "p"); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := m["g"]["g"]; ok {
+			if gdi, err = domainIndex(m, "g"); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	subjects := map[string]bool{}
+	roleSet := map[string]bool{}
+	if ast, ok := m["p"]["p"]; ok {
+		for _, rule := range ast.Policy {
+			if pdi < 0 || rule[pdi] == dom {
+				subjects[rule[0]] = true
+			}
+		}
+	}
+	if ast, ok := m["g"]["g"]; ok {
+		for _, rule := range ast.Policy {
+			if gdi < 0 || rule[gdi] == dom {
+				subjects[rule[0]] = true
+				roleSet[rule[1]] = true
+			}
+		}
+	}
+
+	for name := range subjects {
+		if roleSet[name] {
+			continue
+		}
+		ok, err := enforce(pe.e, request{sub: name, dom: dom, obj: obj, act: act})
+		if err != nil {
+			return nil, nil, fmt.Errorf("subject %s: %v", name, err)
+		}
+		if ok {
+			users = append(users, name)
+		}
+	}
+	for name := range roleSet {
+		ok, err := enforce(pe.e, request{sub: name, dom: dom, obj: obj, act: act})
+		if err != nil {
+			return nil, nil, fmt.Errorf("role %s: %v", name, err)
+		}
+		if ok {
+			roles = append(roles, name)
+		}
+	}
+	sort.Strings(users)
+	sort.Strings(roles)
+	return users, roles, nil
+}
+
+func (s *server) WhoCan(ctx context.Context, req *proto.WhoCanReq) (*proto.WhoCanResp, error) {
+	users, roles, err := s.enforcer.whoCan(req.GetDom(), req.GetObj(), req.GetAct())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &proto.WhoCanResp{Users: users, Roles: roles}, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	proto "casbinsvr/proto"
+	"context"
+	fileadapter "github.com/casbin/casbin/persist/file-adapter"
+	"reflect"
+	"testing"
+)
+
+func TestWhoCan(t *testing.T) {
+	tests := []struct {
+		model, policy string
+		req           proto.WhoCanReq
+		users, roles  []string
+	}{
+		{"rbac_with_deny_model.conf", "rbac_with_deny_policy.csv", proto.WhoCanReq{Obj: "data1", Act: "read"}, []string{"alice", "bob"}, []string{"admin"}},
+		// alice's deny overrides her admin role
+		{"rbac_with_deny_model.conf", "rbac_with_deny_policy.csv", proto.WhoCanReq{Obj: "data1", Act: "write"}, []string{"bob"}, []string{"admin"}},
+		{"rbac_with_deny_model.conf", "rbac_with_deny_policy.csv", proto.WhoCanReq{Obj: "data2", Act: "read"}, nil, nil},
+		{"rbac_with_domains_model.conf", "rbac_with_domains_policy.csv", proto.WhoCanReq{Dom: "tenant1", Obj: "data1", Act: "write"}, []string{"alice"}, []string{"admin"}},
+		{"rbac_with_domains_model.conf", "rbac_with_domains_policy.csv", proto.WhoCanReq{Dom: "tenant2", Obj: "data2", Act: "read"}, []string{"bob"}, []string{"admin"}},
+		{"rbac_with_domains_model.conf", "rbac_with_domains_policy.csv", proto.WhoCanReq{Dom: "tenant2", Obj: "data1", Act: "read"}, nil, nil},
+	}
+	for _, test := range tests {
+		pe, err := newPolicyEnforcer(test.model, test.policy, fileadapter.NewAdapter(test.policy), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &server{enforcer: pe}
+		resp, err := s.WhoCan(context.Background(), &test.req)
+		if err != nil {
+			t.Errorf("%s %v: %v", test.model, test.req, err)
+			continue
+		}
+		if !reflect.DeepEqual(resp.GetUsers(), test.users) || !reflect.DeepEqual(resp.GetRoles(), test.roles) {
+			t.Errorf("%s %v: got users %v, roles %v; want %v, %v", test.model, test.req, resp.GetUsers(), resp.GetRoles(), test.users, test.roles)
+		}
+	}
+}
+
+func TestWhoCanNeedsDomain(t *testing.T) {
+	pe, err := newPolicyEnforcer("rbac_with_domains_model.conf", "rbac_with_domains_policy.csv", fileadapter.NewAdapter("rbac_with_domains_policy.csv"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pe.whoCan("", "data1", "read"); err == nil {
+		t.Error("whoCan without a domain succeeded on a domain model")
+	}
+}
@@ -1,22 +1,56 @@
 package main
 
 import (
+	"casbinsvr/config"
 	gw "casbinsvr/proto"
+	"casbinsvr/tlsconfig"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/golang/glog"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"net"
 	"net/http"
 )
 
 var (
 	// command-line options:
 	// gRPC server endpoint
-	grpcServerEndpoint = flag.String("grpc-server-endpoint",  "localhost:50051", "gRPC server endpoint")
+	grpcServerEndpoint = flag.String("grpc-server-endpoint", "localhost:50051", "gRPC server endpoint")
+	addr               = flag.String("addr", ":7777", "HTTP listen address")
+	// TLS towards the gRPC server is used when any of the files is set
+	grpcCA         = flag.String("grpc-tls-ca", "", "PEM CA bundle to verify the gRPC server with")
+	grpcCert       = flag.String("grpc-tls-cert", "", "PEM client certificate for mutual TLS with the gRPC server")
+	grpcKey        = flag.String("grpc-tls-key", "", "PEM private key of -grpc-tls-cert")
+	grpcServerName = flag.String("grpc-tls-server-name", "", "name to verify the gRPC server certificate against")
+	// HTTPS is served when a certificate is set
+	tlsCert = flag.String("tls-cert", "", "PEM certificate to serve HTTPS with")
+	tlsKey  = flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tlsCA   = flag.String("tls-ca", "", "PEM CA bundle; HTTP clients must present a certificate signed by it")
 )
 
+// metrics of proxied requests, served on /metrics next to the API
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "casbinsvr_gateway_requests_total",
+		Help: "HTTP requests proxied to the gRPC server by status code and method.",
+	}, []string{"code", "method"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "casbinsvr_gateway_request_duration_seconds",
+		Help:    "Latency of HTTP requests proxied to the gRPC server.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"code", "method"})
+)
+
+func init() {
+	prometheus.MustRegister(requestsTotal, requestDuration)
+}
+
 func run() error {
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
@@ -26,17 +60,55 @@ func run() error {
 	// Note: Make sure the gRPC server is running properly and accessible
 	mux := runtime.NewServeMux()
 	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if files := (tlsconfig.Files{Cert: *grpcCert, Key: *grpcKey, CA: *grpcCA}); !files.Empty() {
+		config, err := tlsconfig.Client(files, *grpcServerName)
+		if err != nil {
+			return err
+		}
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(config))}
+	}
 	err := gw.RegisterAccessControlHandlerFromEndpoint(ctx, mux, *grpcServerEndpoint, opts)
 	if err != nil {
 		return err
 	}
 
 	// Start HTTP server (and proxy calls to gRPC server endpoint)
-	return http.ListenAndServe(":7777", mux)
+	handler := http.NewServeMux()
+	handler.Handle("/", promhttp.InstrumentHandlerDuration(requestDuration,
+		promhttp.InstrumentHandlerCounter(requestsTotal, mux)))
+	handler.Handle("/metrics", promhttp.Handler())
+	files := tlsconfig.Files{Cert: *tlsCert, Key: *tlsKey, CA: *tlsCA}
+	if files.Empty() {
+		return http.ListenAndServe(*addr, handler)
+	}
+	config, err := tlsconfig.Server(files)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Addr: *addr, Handler: handler, TLSConfig: config}
+	return srv.ListenAndServeTLS("", "")
+}
+
+// validateConfig rejects settings the gateway could only fail on later.
+func validateConfig() error {
+	for _, a := range []string{*addr, *grpcServerEndpoint} {
+		if _, _, err := net.SplitHostPort(a); err != nil {
+			return fmt.Errorf("%s: %v", a, err)
+		}
+	}
+	if (*tlsCert == "") != (*tlsKey == "") || *tlsCA != "" && *tlsCert == "" {
+		return errors.New("tls-cert and tls-key go together, and tls-ca needs them")
+	}
+	if (*grpcCert == "") != (*grpcKey == "") {
+		return errors.New("grpc-tls-cert and grpc-tls-key go together")
+	}
+	return config.CheckFiles(*tlsCert, *tlsKey, *tlsCA, *grpcCert, *grpcKey, *grpcCA)
 }
 
 func main() {
-	flag.Parse()
+	if err := config.Parse("gateway", validateConfig); err != nil {
+		glog.Fatal(err)
+	}
 	defer glog.Flush()
 	fmt.Println("grpc gateway starting... no panic means ok!")
 	if err := run(); err != nil {
@@ -0,0 +1,216 @@
+// Package tlsconfig builds TLS configurations for the access control server,
+// the gateway and the client from PEM files.
+//
+// Certificates, keys and the server's client CA bundle are re-read when the
+// files change, so rotated certificates take effect on the next handshake
+// without a restart. The files are checked at most once per CHECK_INTERVAL; a
+// rotation that fails to load is logged and the previous certificate is kept.
+// A client's root CA bundle is read once.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// CHECK_INTERVAL is how often the files are checked for changes.
+const CHECK_INTERVAL = 10 * time.Second
+
+// Files names the PEM files of an identity and the CA bundle that verifies
+// the other side of the connection.
+type Files struct {
+	Cert string
+	Key  string
+	CA   string
+}
+
+// Empty reports whether no file is set, i.e. TLS is not configured.
+func (f Files) Empty() bool {
+	return f.Cert == "" && f.Key == "" && f.CA == ""
+}
+
+// Server returns the configuration of a TLS listener presenting Cert and Key.
+// With CA, clients must present a certificate signed by it (mutual TLS).
+func Server(f Files) (*tls.Config, error) {
+	if f.Cert == "" || f.Key == "" {
+		return nil, errors.New("tls: a server needs both a certificate and a key")
+	}
+	kp, err := newKeyPair(f.Cert, f.Key)
+	if err != nil {
+		return nil, err
+	}
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return kp.get(), nil
+		},
+	}
+	if f.CA == "" {
+		return base, nil
+	}
+
+	cas, err := newCertPool(f.CA)
+	if err != nil {
+		return nil, err
+	}
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+	base.ClientCAs = cas.get()
+	// the client CAs are part of the config itself, so hand out a fresh copy
+	// whenever they may have changed
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.ClientCAs = cas.get()
+		return c, nil
+	}
+	return base, nil
+}
+
+// Client returns the configuration for dialing serverName, which may be empty
+// to use the host of the dialed address. The server is verified against CA,
+// or the system roots if CA is empty. With Cert and Key the client presents
+// them for mutual TLS.
+func Client(f Files, serverName string) (*tls.Config, error) {
+	c := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if f.CA != "" {
+		cas, err := newCertPool(f.CA)
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs = cas.get()
+	}
+	if f.Cert == "" && f.Key == "" {
+		return c, nil
+	}
+	if f.Cert == "" || f.Key == "" {
+		return nil, errors.New("tls: a client certificate needs both a certificate and a key")
+	}
+	kp, err := newKeyPair(f.Cert, f.Key)
+	if err != nil {
+		return nil, err
+	}
+	c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return kp.get(), nil
+	}
+	return c, nil
+}
+
+// keyPair is a certificate and key reloaded when their files change.
+type keyPair struct {
+	files *watchedFiles
+	cert  *tls.Certificate
+}
+
+func newKeyPair(certFile, keyFile string) (*keyPair, error) {
+	kp := &keyPair{}
+	kp.files = &watchedFiles{paths: []string{certFile, keyFile}, load: func() error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		kp.cert = &cert
+		return nil
+	}}
+	if err := kp.files.check(); err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+func (kp *keyPair) get() *tls.Certificate {
+	kp.files.mu.Lock()
+	defer kp.files.mu.Unlock()
+	kp.files.refresh()
+	return kp.cert
+}
+
+// certPool is a CA bundle reloaded when its file changes.
+type certPool struct {
+	files *watchedFiles
+	pool  *x509.CertPool
+}
+
+func newCertPool(path string) (*certPool, error) {
+	cp := &certPool{}
+	cp.files = &watchedFiles{paths: []string{path}, load: func() error {
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", path)
+		}
+		cp.pool = pool
+		return nil
+	}}
+	if err := cp.files.check(); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func (cp *certPool) get() *x509.CertPool {
+	cp.files.mu.Lock()
+	defer cp.files.mu.Unlock()
+	cp.files.refresh()
+	return cp.pool
+}
+
+// watchedFiles calls load again when any of its files was modified.
+type watchedFiles struct {
+	paths []string
+	load  func() error
+
+	mu      sync.Mutex
+	checked time.Time
+	mods    []time.Time
+}
+
+// check loads the files if they changed since they were last seen. A failed
+// load is not retried until they change again.
+func (w *watchedFiles) check() error {
+	mods := make([]time.Time, len(w.paths))
+	for i, path := range w.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		mods[i] = info.ModTime()
+	}
+	if w.mods != nil && equalTimes(mods, w.mods) {
+		return nil
+	}
+	w.mods = mods
+	if err := w.load(); err != nil {
+		return fmt.Errorf("tls: load %v: %v", w.paths, err)
+	}
+	return nil
+}
+
+// refresh runs check at most once per CHECK_INTERVAL and keeps the loaded
+// files on failure. Callers hold mu.
+func (w *watchedFiles) refresh() {
+	if time.Since(w.checked) < CHECK_INTERVAL {
+		return
+	}
+	w.checked = time.Now()
+	if err := w.check(); err != nil {
+		log.Println(err, "- keeping the previous files")
+	}
+}
+
+func equalTimes(a, b []time.Time) bool {
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}